@@ -0,0 +1,58 @@
+package board
+
+// Attackers returns the pieces of the attacker that strike the square. Sliding pieces are blocked
+// by the occupied squares, which are usually bb.Occupied, but can leave out a piece that is about
+// to move.
+func (bb *Bitboards) Attackers(index int, attacker Color, occupied Bitboard) Bitboard {
+	pieces := &bb.Pieces[attacker]
+
+	// pawns strike diagonally forward, so we look diagonally backward from their point of view
+	return pawnAttacks[attacker^1][index]&pieces[PAWN] |
+		knightAttacks[index]&pieces[KNIGHT] |
+		kingAttacks[index]&pieces[KING] |
+		BishopAttacks(index, occupied)&(pieces[BISHOP]|pieces[QUEEN]) |
+		RookAttacks(index, occupied)&(pieces[ROOK]|pieces[QUEEN])
+}
+
+// IsSquareAttacked tells whether any piece of the attacker strikes the square.
+func (bb *Bitboards) IsSquareAttacked(index int, attacker Color) bool {
+	return bb.Attackers(index, attacker, bb.Occupied) != 0
+}
+
+// InCheck tells whether the king of the side to move is attacked. A side without king is never in
+// check.
+func (bb *Bitboards) InCheck() bool {
+	return bb.Checkers() != 0
+}
+
+// Checkers returns the pieces giving check to the side to move.
+func (bb *Bitboards) Checkers() Bitboard {
+	if bb.Pieces[bb.Side][KING] == 0 {
+		return 0
+	}
+
+	return bb.Attackers(bb.King(bb.Side), bb.Side^1, bb.Occupied)
+}
+
+// Pinned returns the pieces of the color that stand between their king and an enemy rook, bishop
+// or queen attacking along that line. Such a piece may only move on Line(king, square).
+func (bb *Bitboards) Pinned(color Color) Bitboard {
+	if bb.Pieces[color][KING] == 0 {
+		return 0
+	}
+
+	king := bb.King(color)
+	enemy := &bb.Pieces[color^1]
+	// the enemy sliders that would attack the king on an empty board
+	snipers := RookAttacks(king, 0)&(enemy[ROOK]|enemy[QUEEN]) | BishopAttacks(king, 0)&(enemy[BISHOP]|enemy[QUEEN])
+
+	var pinned Bitboard
+	for snipers != 0 {
+		blockers := between[king][snipers.Pop()] & bb.Occupied
+		if blockers.Count() == 1 && blockers&bb.Colors[color] != 0 {
+			pinned |= blockers
+		}
+	}
+
+	return pinned
+}
@@ -0,0 +1,92 @@
+package board_test
+
+import (
+	"chessBot/board"
+	"chessBot/fen"
+	"testing"
+)
+
+func bitboardsFromFen(t *testing.T, fenString string) *board.Bitboards {
+	b, err := fen.FenToBoard(fenString)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return board.NewBitboards(b)
+}
+
+func index(position string) int {
+	return board.PosFromString(position).Index()
+}
+
+func TestIsSquareAttacked(t *testing.T) {
+	bb := bitboardsFromFen(t, "4k3/8/8/3p4/8/2N5/8/R3K3 w - - 0 1")
+
+	tests := []struct {
+		square   string
+		attacker board.Color
+		expected bool
+	}{
+		{"a8", board.WHITE, true},
+		{"e4", board.WHITE, true},
+		{"e4", board.BLACK, true},
+		{"d4", board.BLACK, false},
+		{"f1", board.WHITE, true},
+		{"h1", board.WHITE, false},
+		{"d7", board.BLACK, true},
+	}
+	for _, test := range tests {
+		if actual := bb.IsSquareAttacked(index(test.square), test.attacker); actual != test.expected {
+			t.Errorf("expected %s attacked by %d to be %v", test.square, test.attacker, test.expected)
+		}
+	}
+}
+
+func TestCheckers(t *testing.T) {
+	bb := bitboardsFromFen(t, "4k3/8/8/8/1b6/8/3N4/r3K3 w - - 0 1")
+	if expected := bitboardOf("a1"); bb.Checkers() != expected {
+		t.Errorf("expected the rook to give check, but got\n%s", bb.Checkers())
+	}
+	if !bb.InCheck() {
+		t.Error("expected white to be in check")
+	}
+
+	bb = bitboardsFromFen(t, "4k3/8/8/8/1b6/8/8/r3K3 w - - 0 1")
+	if expected := bitboardOf("a1", "b4"); bb.Checkers() != expected {
+		t.Errorf("expected a double check, but got\n%s", bb.Checkers())
+	}
+
+	bb = bitboardsFromFen(t, fen.STARTPOSFEN)
+	if bb.InCheck() {
+		t.Error("expected no check in the start position")
+	}
+}
+
+func TestPinned(t *testing.T) {
+	bb := bitboardsFromFen(t, "4k3/4r3/8/b7/8/8/3NR3/4K3 w - - 0 1")
+	// the bishop pins the knight, and the rooks pin each other
+	if expected := bitboardOf("d2", "e2"); bb.Pinned(board.WHITE) != expected {
+		t.Errorf("expected the knight and the rook to be pinned, but got\n%s", bb.Pinned(board.WHITE))
+	}
+	if expected := bitboardOf("e7"); bb.Pinned(board.BLACK) != expected {
+		t.Errorf("expected the black rook to be pinned, but got\n%s", bb.Pinned(board.BLACK))
+	}
+
+	// two pieces between king and slider are no pin
+	bb = bitboardsFromFen(t, "4k3/4r3/8/8/4P3/8/4R3/4K3 w - - 0 1")
+	if bb.Pinned(board.WHITE) != 0 {
+		t.Errorf("expected no pin behind two pieces, but got\n%s", bb.Pinned(board.WHITE))
+	}
+}
+
+func TestBetweenAndLine(t *testing.T) {
+	if expected := bitboardOf("c3", "d4", "e5"); board.Between(index("b2"), index("f6")) != expected {
+		t.Errorf("expected the diagonal between b2 and f6, but got\n%s", board.Between(index("b2"), index("f6")))
+	}
+	if board.Between(index("b2"), index("c4")) != 0 {
+		t.Error("expected nothing between squares on different lines")
+	}
+	if expected := bitboardOf("a1", "b2", "c3", "d4", "e5", "f6", "g7", "h8"); board.Line(index("f6"), index("c3")) != expected {
+		t.Errorf("expected the long diagonal, but got\n%s", board.Line(index("f6"), index("c3")))
+	}
+}
@@ -0,0 +1,188 @@
+package board
+
+import (
+	"math/bits"
+	"strings"
+)
+
+// Bitboard is a set of squares, bit 0 is a1, bit 7 h1 and bit 63 h8, the same order as
+// Position.Index.
+type Bitboard uint64
+
+const (
+	FileA Bitboard = 0x0101010101010101
+	FileH Bitboard = FileA << 7
+	Rank1 Bitboard = 0xff
+	Rank8 Bitboard = Rank1 << 56
+)
+
+// SquareBitboard returns the set holding only the square with the index.
+func SquareBitboard(index int) Bitboard {
+	return Bitboard(1) << uint(index)
+}
+
+// Has tells whether the square with the index is in the set.
+func (b Bitboard) Has(index int) bool {
+	return b&SquareBitboard(index) != 0
+}
+
+func (b Bitboard) Count() int {
+	return bits.OnesCount64(uint64(b))
+}
+
+// First returns the index of the lowest square in the set, 64 if it is empty.
+func (b Bitboard) First() int {
+	return bits.TrailingZeros64(uint64(b))
+}
+
+// Last returns the index of the highest square in the set, -1 if it is empty.
+func (b Bitboard) Last() int {
+	return 63 - bits.LeadingZeros64(uint64(b))
+}
+
+// Pop removes the lowest square from the set and returns its index.
+func (b *Bitboard) Pop() int {
+	index := b.First()
+	*b &= *b - 1
+
+	return index
+}
+
+func (b Bitboard) String() string {
+	var out strings.Builder
+	for rank := 7; rank >= 0; rank-- {
+		for file := 0; file < 8; file++ {
+			if b.Has(rank*8 + file) {
+				out.WriteString("x")
+			} else {
+				out.WriteString(".")
+			}
+		}
+		out.WriteString("\n")
+	}
+
+	return out.String()
+}
+
+// the directions of the rays, the first four go to higher square indexes
+const (
+	north = iota
+	east
+	northEast
+	northWest
+	south
+	west
+	southWest
+	southEast
+)
+
+var raySteps = [8][2]int{{0, 1}, {1, 0}, {1, 1}, {-1, 1}, {0, -1}, {-1, 0}, {-1, -1}, {1, -1}}
+
+var knightAttacks [64]Bitboard
+var kingAttacks [64]Bitboard
+var pawnAttacks [2][64]Bitboard
+
+// rays holds the squares from a square to the edge of the board in each direction, without the
+// square itself. The sliding attacks are the ray up to the first blocker, found by cutting off
+// the ray behind it.
+var rays [8][64]Bitboard
+
+var between [64][64]Bitboard
+var lines [64][64]Bitboard
+
+func init() {
+	for index := 0; index < 64; index++ {
+		file, rank := index&7, index>>3
+
+		for _, step := range [][2]int{{1, 2}, {2, 1}, {2, -1}, {1, -2}, {-1, -2}, {-2, -1}, {-2, 1}, {-1, 2}} {
+			knightAttacks[index] |= squareAt(file+step[0], rank+step[1])
+		}
+		for _, step := range raySteps {
+			kingAttacks[index] |= squareAt(file+step[0], rank+step[1])
+		}
+		pawnAttacks[WHITE][index] = squareAt(file-1, rank+1) | squareAt(file+1, rank+1)
+		pawnAttacks[BLACK][index] = squareAt(file-1, rank-1) | squareAt(file+1, rank-1)
+
+		for direction, step := range raySteps {
+			for f, r := file+step[0], rank+step[1]; f >= 0 && f < 8 && r >= 0 && r < 8; f, r = f+step[0], r+step[1] {
+				rays[direction][index] |= squareAt(f, r)
+			}
+		}
+	}
+
+	for index := 0; index < 64; index++ {
+		for direction := range rays {
+			// the opposite direction is four steps further in the list
+			opposite := (direction + 4) % 8
+			line := rays[direction][index] | rays[opposite][index] | SquareBitboard(index)
+			squares := rays[direction][index]
+			for squares != 0 {
+				other := squares.Pop()
+				between[index][other] = rays[direction][index] & rays[opposite][other]
+				lines[index][other] = line
+			}
+		}
+	}
+}
+
+// squareAt returns the square as a set, or the empty set if it is off the board.
+func squareAt(file int, rank int) Bitboard {
+	if file < 0 || file > 7 || rank < 0 || rank > 7 {
+		return 0
+	}
+
+	return SquareBitboard(rank*8 + file)
+}
+
+func KnightAttacks(index int) Bitboard {
+	return knightAttacks[index]
+}
+
+func KingAttacks(index int) Bitboard {
+	return kingAttacks[index]
+}
+
+// PawnAttacks returns the squares a pawn of the color attacks from the square.
+func PawnAttacks(color Color, index int) Bitboard {
+	return pawnAttacks[color][index]
+}
+
+// BishopAttacks returns the squares a bishop attacks from the square, up to and including the
+// first occupied square in each direction.
+func BishopAttacks(index int, occupied Bitboard) Bitboard {
+	return rayAttacks(northEast, index, occupied) | rayAttacks(northWest, index, occupied) |
+		rayAttacks(southEast, index, occupied) | rayAttacks(southWest, index, occupied)
+}
+
+func RookAttacks(index int, occupied Bitboard) Bitboard {
+	return rayAttacks(north, index, occupied) | rayAttacks(east, index, occupied) |
+		rayAttacks(south, index, occupied) | rayAttacks(west, index, occupied)
+}
+
+func QueenAttacks(index int, occupied Bitboard) Bitboard {
+	return BishopAttacks(index, occupied) | RookAttacks(index, occupied)
+}
+
+func rayAttacks(direction int, index int, occupied Bitboard) Bitboard {
+	ray := rays[direction][index]
+	blockers := ray & occupied
+	if blockers == 0 {
+		return ray
+	}
+	if direction < south {
+		return ray ^ rays[direction][blockers.First()]
+	}
+
+	return ray ^ rays[direction][blockers.Last()]
+}
+
+// Between returns the squares between two squares on the same rank, file or diagonal, the empty set
+// if they are not on one line.
+func Between(from int, to int) Bitboard {
+	return between[from][to]
+}
+
+// Line returns the whole rank, file or diagonal both squares are on, the empty set if there is none.
+func Line(from int, to int) Bitboard {
+	return lines[from][to]
+}
@@ -0,0 +1,200 @@
+package board
+
+// Bitboards holds a position as sets of squares for each color and piece kind. It is the board the
+// engine searches on, as it is much faster to generate moves on than Board. Copying the struct
+// copies the position, which is the cheapest way to take a move back.
+type Bitboards struct {
+	Pieces   [2][6]Bitboard
+	Colors   [2]Bitboard
+	Occupied Bitboard
+
+	Side Color
+	// Castling has the bit 1<<c set for each Castling c that is still possible.
+	Castling uint8
+	// EnPassant is the index of the en passant square, -1 if there is none.
+	EnPassant  int
+	HalfTurns  int
+	TurnNumber int
+	// Hash is the same zobrist key Board.Hash returns for the position.
+	Hash uint64
+
+	// squares holds the piece on each square as color<<3|kind+1, 0 for an empty square
+	squares [64]uint8
+}
+
+// castlingRightsLost holds the castling rights that are gone once a piece moves from or to a square.
+var castlingRightsLost [64]uint8
+
+func init() {
+	for _, castling := range CastlingMoves {
+		castlingRightsLost[castling.KingFrom.Index()] |= 1 << uint(castling.Castling)
+		castlingRightsLost[castling.RookFrom.Index()] |= 1 << uint(castling.Castling)
+	}
+}
+
+// NewBitboards converts the board.
+func NewBitboards(b *Board) *Bitboards {
+	bb := &Bitboards{
+		Side:       b.Side,
+		EnPassant:  -1,
+		HalfTurns:  b.HalfTurns,
+		TurnNumber: b.TurnNumber,
+	}
+	for index, cell := range b.Cells {
+		if cell.Occupant != nil {
+			bb.put(index, cell.Occupant.Kind, cell.Occupant.Color)
+		}
+	}
+	for _, c := range b.Castling {
+		bb.Castling |= 1 << uint(c)
+	}
+	if b.EnPassant != nil {
+		bb.EnPassant = b.EnPassant.Index()
+		bb.Hash ^= bb.enPassantHash()
+	}
+	bb.Hash ^= zobristCastlingRights[bb.Castling]
+	if b.Side == BLACK {
+		bb.Hash ^= zobristSide
+	}
+
+	return bb
+}
+
+// Board converts the position back.
+func (bb *Bitboards) Board() *Board {
+	b := NewBoard()
+	for index := 0; index < 64; index++ {
+		if kind, color, ok := bb.PieceAt(index); ok {
+			b.SetPieceAt(*PositionFromIndex(index), NewPiece(kind, color))
+		}
+	}
+	b.Side = bb.Side
+	for _, castling := range CastlingMoves {
+		if bb.CanCastle(castling.Castling) {
+			b.Castling = append(b.Castling, castling.Castling)
+		}
+	}
+	if bb.EnPassant >= 0 {
+		b.EnPassant = PositionFromIndex(bb.EnPassant)
+	}
+	b.HalfTurns = bb.HalfTurns
+	b.TurnNumber = bb.TurnNumber
+	b.RefreshHash()
+
+	return b
+}
+
+// PieceAt returns the piece on the square with the index, ok is false if it is empty.
+func (bb *Bitboards) PieceAt(index int) (kind ChessPieceKind, color Color, ok bool) {
+	code := bb.squares[index]
+	if code == 0 {
+		return PAWN, WHITE, false
+	}
+
+	return ChessPieceKind(code&7 - 1), Color(code >> 3), true
+}
+
+func (bb *Bitboards) CanCastle(c Castling) bool {
+	return bb.Castling&(1<<uint(c)) != 0
+}
+
+// King returns the index of the square of the king of the color.
+func (bb *Bitboards) King(color Color) int {
+	return bb.Pieces[color][KING].First()
+}
+
+// enPassantHash hashes the en passant square like Board does, only if a pawn of the side to move
+// can capture on it.
+func (bb *Bitboards) enPassantHash() uint64 {
+	if bb.EnPassant < 0 || pawnAttacks[bb.Side^1][bb.EnPassant]&bb.Pieces[bb.Side][PAWN] == 0 {
+		return 0
+	}
+
+	return zobristEnPassant[bb.EnPassant&7]
+}
+
+func (bb *Bitboards) put(index int, kind ChessPieceKind, color Color) {
+	square := SquareBitboard(index)
+	bb.Pieces[color][kind] |= square
+	bb.Colors[color] |= square
+	bb.Occupied |= square
+	bb.squares[index] = uint8(color)<<3 | uint8(kind+1)
+	bb.Hash ^= zobristPieces[color][kind][index]
+}
+
+func (bb *Bitboards) remove(index int, kind ChessPieceKind, color Color) {
+	square := SquareBitboard(index)
+	bb.Pieces[color][kind] &^= square
+	bb.Colors[color] &^= square
+	bb.Occupied &^= square
+	bb.squares[index] = 0
+	bb.Hash ^= zobristPieces[color][kind][index]
+}
+
+// MakeMove plays the move like Board.MakeMove does. There is no way to take it back, so the
+// position has to be copied before if it is needed again.
+func (bb *Bitboards) MakeMove(move Move) {
+	if bb.EnPassant >= 0 {
+		bb.Hash ^= bb.enPassantHash()
+		bb.EnPassant = -1
+	}
+	if move.IsNull() {
+		bb.passTurn()
+		return
+	}
+
+	from, to := move.From.Index(), move.To.Index()
+	kind, color, _ := bb.PieceAt(from)
+	enemy := color ^ 1
+	resetsClock := kind == PAWN
+
+	if capturedKind, _, ok := bb.PieceAt(to); ok {
+		bb.remove(to, capturedKind, enemy)
+		resetsClock = true
+	} else if kind == PAWN && (from-to)&7 != 0 {
+		// en passant: the captured pawn stands next to the moving one
+		bb.remove(to&7|from&^7, PAWN, enemy)
+	}
+
+	bb.remove(from, kind, color)
+	if move.Promotion != PAWN {
+		bb.put(to, move.Promotion, color)
+	} else {
+		bb.put(to, kind, color)
+	}
+
+	if kind == KING && (to-from == 2 || from-to == 2) {
+		// the rook jumps over the king, from the corner next to the king's target square
+		rookFrom, rookTo := from+3, from+1
+		if to < from {
+			rookFrom, rookTo = from-4, from-1
+		}
+		bb.remove(rookFrom, ROOK, color)
+		bb.put(rookTo, ROOK, color)
+	}
+
+	if lost := castlingRightsLost[from] | castlingRightsLost[to]; bb.Castling&lost != 0 {
+		bb.Hash ^= zobristCastlingRights[bb.Castling]
+		bb.Castling &^= lost
+		bb.Hash ^= zobristCastlingRights[bb.Castling]
+	}
+
+	if kind == PAWN && (to-from == 16 || from-to == 16) {
+		bb.EnPassant = (from + to) / 2
+	}
+
+	bb.passTurn()
+	bb.Hash ^= bb.enPassantHash()
+	if resetsClock {
+		bb.HalfTurns = 0
+	}
+}
+
+func (bb *Bitboards) passTurn() {
+	bb.HalfTurns++
+	if bb.Side == BLACK {
+		bb.TurnNumber++
+	}
+	bb.Side ^= 1
+	bb.Hash ^= zobristSide
+}
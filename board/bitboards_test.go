@@ -0,0 +1,91 @@
+package board_test
+
+import (
+	"chessBot/board"
+	"chessBot/engine"
+	"chessBot/fen"
+	"math/rand"
+	"testing"
+)
+
+func TestBitboardsRoundTrip(t *testing.T) {
+	for _, fenString := range []string{
+		fen.STARTPOSFEN,
+		"r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1",
+		"rnbqkbnr/ppp1p1pp/8/3pPp2/8/8/PPPP1PPP/RNBQKBNR w KQkq f6 0 3",
+		"8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 b - - 12 40",
+		// no pawn can capture on e3, so the square is left out of the hash
+		"rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1",
+	} {
+		b, err := fen.FenToBoard(fenString)
+		if err != nil {
+			t.Fatal(err)
+		}
+		bb := board.NewBitboards(b)
+		if bb.Hash != b.Hash() {
+			t.Errorf("%s: expected the hash of the board", fenString)
+		}
+		if actual := fen.BoardToFen(bb.Board()); actual != fenString {
+			t.Errorf("expected %s, but got %s", fenString, actual)
+		}
+	}
+}
+
+func TestBitboardsMakeMove(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+
+	for _, fenString := range []string{
+		fen.STARTPOSFEN,
+		"r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1",
+		"r3k2r/Pppp1ppp/1b3nbN/nP6/BBP1P3/q4N2/Pp1P2PP/R2Q1RK1 w kq - 0 1",
+	} {
+		for game := 0; game < 20; game++ {
+			b, _ := fen.FenToBoard(fenString)
+			bb := board.NewBitboards(b)
+			for ply := 0; ply < 150; ply++ {
+				moves := engine.LegalMoves(b)
+				if len(moves) == 0 {
+					break
+				}
+				move := moves[random.Intn(len(moves))]
+				if random.Intn(20) == 0 {
+					move = board.NullMove
+				}
+				b.MakeMove(move)
+				bb.MakeMove(move)
+
+				if bb.Hash != b.Hash() {
+					t.Fatalf("expected the hash of the board after %s in game %d from %s", move, game, fenString)
+				}
+				if expected, actual := fen.BoardToFen(b), fen.BoardToFen(bb.Board()); actual != expected {
+					t.Fatalf("expected %s after %s, but got %s", expected, move, actual)
+				}
+			}
+		}
+	}
+}
+
+func TestSlidingAttacks(t *testing.T) {
+	d4 := board.PosFromString("d4").Index()
+	occupied := board.SquareBitboard(board.PosFromString("d6").Index()) |
+		board.SquareBitboard(board.PosFromString("f4").Index()) |
+		board.SquareBitboard(board.PosFromString("b2").Index())
+
+	expected := []string{"d1", "d2", "d3", "d5", "d6", "a4", "b4", "c4", "e4", "f4"}
+	if actual := board.RookAttacks(d4, occupied); actual != bitboardOf(expected...) {
+		t.Errorf("expected the rook to attack %v, but got\n%s", expected, actual)
+	}
+	expected = []string{"b2", "c3", "e5", "f6", "g7", "h8", "a7", "b6", "c5", "e3", "f2", "g1"}
+	if actual := board.BishopAttacks(d4, occupied); actual != bitboardOf(expected...) {
+		t.Errorf("expected the bishop to attack %v, but got\n%s", expected, actual)
+	}
+}
+
+func bitboardOf(positions ...string) board.Bitboard {
+	var bitboard board.Bitboard
+	for _, position := range positions {
+		bitboard |= board.SquareBitboard(board.PosFromString(position).Index())
+	}
+
+	return bitboard
+}
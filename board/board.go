@@ -1,6 +1,7 @@
 package board
 
 import (
+	"fmt"
 	"strconv"
 )
 
@@ -33,6 +34,24 @@ type Position struct {
 	Rank int
 }
 
+// CastlingMove describes the squares king and rook travel when castling.
+type CastlingMove struct {
+	Castling Castling
+	Side     Color
+	KingFrom Position
+	KingTo   Position
+	RookFrom Position
+	RookTo   Position
+}
+
+// CastlingMoves is indexed by Castling.
+var CastlingMoves = []CastlingMove{
+	{Castling: WHITE_KINGSIDE, Side: WHITE, KingFrom: Position{E, 1}, KingTo: Position{G, 1}, RookFrom: Position{H, 1}, RookTo: Position{F, 1}},
+	{Castling: WHITE_QUEENSIDE, Side: WHITE, KingFrom: Position{E, 1}, KingTo: Position{C, 1}, RookFrom: Position{A, 1}, RookTo: Position{D, 1}},
+	{Castling: BLACK_KINGSIDE, Side: BLACK, KingFrom: Position{E, 8}, KingTo: Position{G, 8}, RookFrom: Position{H, 8}, RookTo: Position{F, 8}},
+	{Castling: BLACK_QUEENSIDE, Side: BLACK, KingFrom: Position{E, 8}, KingTo: Position{C, 8}, RookFrom: Position{A, 8}, RookTo: Position{D, 8}},
+}
+
 func (p Position) String() string {
 	var out string
 	switch p.File {
@@ -58,6 +77,11 @@ func (p Position) String() string {
 	return out
 }
 
+// Index returns the index of the position in a 64 cell board.
+func (p Position) Index() int {
+	return indexFromFileAndRank(p.File, p.Rank)
+}
+
 func (p *Position) SameAs(p2 *Position) bool {
 	if p == nil && p2 == nil {
 		return true
@@ -69,6 +93,21 @@ func (p *Position) SameAs(p2 *Position) bool {
 	return p.File == p2.File && p.Rank == p2.Rank
 }
 
+// ParsePosition parses a position like "e4".
+func ParsePosition(pos string) (Position, error) {
+	if len(pos) != 2 {
+		return Position{}, fmt.Errorf("invalid position %q: expected file and rank", pos)
+	}
+	if pos[0] < 'a' || pos[0] > 'h' {
+		return Position{}, fmt.Errorf("invalid position %q: unknown file %q", pos, pos[0])
+	}
+	if pos[1] < '1' || pos[1] > '8' {
+		return Position{}, fmt.Errorf("invalid position %q: unknown rank %q", pos, pos[1])
+	}
+
+	return PosFromString(pos), nil
+}
+
 func PosFromString(pos string) Position {
 	position := Position{}
 	if len(pos) != 2 {
@@ -103,34 +142,6 @@ func PosFromString(pos string) Position {
 	return position
 }
 
-type Move struct {
-	From Position
-	To Position
-}
-
-func (m Move) String() string {
-	var out string
-	for _, p := range []Position{m.From, m.To} {
-		out += p.String()
-	}
-
-	return out
-}
-
-func (m Move) Invert() Move {
-	return Move{m.To, m.From}
-}
-
-func MoveFromString(moveStr string) Move {
-	move := Move{}
-	fromString := string(moveStr[0]) + string(moveStr[1])
-	toString := string(moveStr[2]) + string(moveStr[3])
-	move.From = PosFromString(fromString)
-	move.To = PosFromString(toString)
-
-	return move
-}
-
 type Mailbox120 [120]int
 type Mailbox64 [64]int
 
@@ -145,6 +156,8 @@ type Board struct {
 	EnPassant  *Position
 	HalfTurns  int
 	TurnNumber int
+
+	hash uint64
 }
 
 func NewMailbox120() *Mailbox120 {
@@ -184,6 +197,26 @@ func NewBoard() *Board {
 	}
 }
 
+// Copy returns a board with the same state that can be changed without affecting b.
+func (b *Board) Copy() *Board {
+	c := &Board{
+		Cells:      make([]Cell, len(b.Cells)),
+		Side:       b.Side,
+		Castling:   make([]Castling, len(b.Castling)),
+		HalfTurns:  b.HalfTurns,
+		TurnNumber: b.TurnNumber,
+		hash:       b.hash,
+	}
+	copy(c.Cells, b.Cells)
+	copy(c.Castling, b.Castling)
+	if b.EnPassant != nil {
+		ep := *b.EnPassant
+		c.EnPassant = &ep
+	}
+
+	return c
+}
+
 func (b *Board) IsCastlingPossible(c Castling) bool {
 	for _, possibleCastling := range b.Castling {
 		if possibleCastling == c {
@@ -195,6 +228,7 @@ func (b *Board) IsCastlingPossible(c Castling) bool {
 }
 
 func (b *Board) SwitchSide() {
+	b.hash ^= zobristSide
 	if b.Side == WHITE {
 		b.Side = BLACK
 		return
@@ -202,6 +236,7 @@ func (b *Board) SwitchSide() {
 	b.Side = WHITE
 }
 
+// Move only moves the piece from one cell to another. Use MakeMove to play a move by the rules.
 func (b *Board) Move(move Move) {
 	pieceToMove := b.PieceAt(move.From)
 	b.ClearPieceAt(move.From)
@@ -213,11 +248,18 @@ func (b *Board) PieceAt(p Position) *Piece {
 }
 
 func (b *Board) SetPieceAt(p Position, piece *Piece) {
+	b.ClearPieceAt(p)
+	if piece != nil {
+		b.hash ^= zobristPieces[piece.Color][piece.Kind][p.Index()]
+	}
 	b.Cells[indexFromFileAndRank(p.File, p.Rank)].Occupant = piece
-	b.Cells[indexFromFileAndRank(p.File, p.Rank)].Occupied = true
+	b.Cells[indexFromFileAndRank(p.File, p.Rank)].Occupied = piece != nil
 }
 
 func (b *Board) ClearPieceAt(p Position) {
+	if piece := b.PieceAt(p); piece != nil {
+		b.hash ^= zobristPieces[piece.Color][piece.Kind][p.Index()]
+	}
 	b.Cells[indexFromFileAndRank(p.File, p.Rank)].Occupant = nil
 	b.Cells[indexFromFileAndRank(p.File, p.Rank)].Occupied = false
 }
@@ -0,0 +1,270 @@
+package board
+
+import (
+	"fmt"
+)
+
+type Move struct {
+	From      Position
+	To        Position
+	Promotion ChessPieceKind // PAWN means the move is no promotion
+
+	IsCapture        bool
+	Captured         ChessPieceKind
+	IsCastling       bool
+	IsEnPassant      bool
+	IsDoublePawnPush bool
+}
+
+// NullMove passes the turn to the other side. It is written as "0000".
+var NullMove = Move{}
+
+func (m Move) IsNull() bool {
+	return m.From.Rank == 0 && m.To.Rank == 0
+}
+
+// SameAs reports whether both moves go from the same cell to the same cell with the same promotion,
+// no matter which of the additional flags are set.
+func (m Move) SameAs(m2 Move) bool {
+	return m.From == m2.From && m.To == m2.To && m.Promotion == m2.Promotion
+}
+
+// String returns the move in long algebraic notation as used by UCI, e.g. "e2e4" or "a7a8q".
+func (m Move) String() string {
+	if m.IsNull() {
+		return "0000"
+	}
+
+	var out string
+	for _, p := range []Position{m.From, m.To} {
+		out += p.String()
+	}
+
+	switch m.Promotion {
+	case KNIGHT:
+		out += "n"
+	case BISHOP:
+		out += "b"
+	case ROOK:
+		out += "r"
+	case QUEEN:
+		out += "q"
+	}
+
+	return out
+}
+
+func (m Move) Invert() Move {
+	return Move{From: m.To, To: m.From}
+}
+
+// ParseMove parses a move in long algebraic notation. Only From, To and Promotion are known from
+// the notation, so the returned move carries no capture or special move flags.
+func ParseMove(moveStr string) (Move, error) {
+	if moveStr == "0000" {
+		return NullMove, nil
+	}
+	if len(moveStr) != 4 && len(moveStr) != 5 {
+		return Move{}, fmt.Errorf("invalid move %q: expected 4 or 5 characters", moveStr)
+	}
+
+	move := Move{}
+	var err error
+	move.From, err = ParsePosition(moveStr[0:2])
+	if err != nil {
+		return Move{}, fmt.Errorf("invalid move %q: %s", moveStr, err)
+	}
+	move.To, err = ParsePosition(moveStr[2:4])
+	if err != nil {
+		return Move{}, fmt.Errorf("invalid move %q: %s", moveStr, err)
+	}
+
+	if len(moveStr) == 5 {
+		switch moveStr[4] {
+		case 'n':
+			move.Promotion = KNIGHT
+		case 'b':
+			move.Promotion = BISHOP
+		case 'r':
+			move.Promotion = ROOK
+		case 'q':
+			move.Promotion = QUEEN
+		default:
+			return Move{}, fmt.Errorf("invalid move %q: unknown promotion %q", moveStr, moveStr[4])
+		}
+	}
+
+	return move, nil
+}
+
+// MoveFromString works like ParseMove, but returns the null move for invalid input.
+func MoveFromString(moveStr string) Move {
+	move, err := ParseMove(moveStr)
+	if err != nil {
+		return NullMove
+	}
+
+	return move
+}
+
+// Undo records everything MakeMove changed, so UnmakeMove can restore the previous state.
+type Undo struct {
+	Move       Move
+	Moved      *Piece
+	Captured   *Piece
+	CapturedAt Position
+	Castling   []Castling
+	EnPassant  *Position
+	HalfTurns  int
+	TurnNumber int
+	Hash       uint64
+}
+
+// MakeMove applies the move with all rules of chess: it moves the rook when castling, removes
+// the pawn captured en passant, promotes and updates side, castling rights, en passant square and
+// the move counters. The move has to be at least pseudo legal for the side to move. Making the null
+// move only passes the turn.
+func (b *Board) MakeMove(move Move) Undo {
+	undo := Undo{
+		Move:       move,
+		Castling:   b.Castling,
+		EnPassant:  b.EnPassant,
+		HalfTurns:  b.HalfTurns,
+		TurnNumber: b.TurnNumber,
+		Hash:       b.hash,
+	}
+	b.hash ^= b.enPassantHash()
+	if move.IsNull() {
+		b.EnPassant = nil
+		b.passTurn()
+		return undo
+	}
+	undo.Moved = b.PieceAt(move.From)
+	piece := undo.Moved
+
+	undo.Captured = b.PieceAt(move.To)
+	undo.CapturedAt = move.To
+	if piece.Kind == PAWN && undo.Captured == nil && move.From.File != move.To.File {
+		// en passant: the captured pawn stands next to the moving one
+		undo.CapturedAt = Position{File: move.To.File, Rank: move.From.Rank}
+		undo.Captured = b.PieceAt(undo.CapturedAt)
+		b.ClearPieceAt(undo.CapturedAt)
+	}
+
+	b.ClearPieceAt(move.From)
+	if move.Promotion != PAWN {
+		b.SetPieceAt(move.To, NewPiece(move.Promotion, piece.Color))
+	} else {
+		b.SetPieceAt(move.To, piece)
+	}
+
+	if castling, ok := castlingMoveFor(piece, move); ok {
+		rook := b.PieceAt(castling.RookFrom)
+		b.ClearPieceAt(castling.RookFrom)
+		b.SetPieceAt(castling.RookTo, rook)
+	}
+
+	b.hash ^= castlingHash(b.Castling)
+	b.Castling = b.remainingCastling(piece, move)
+	b.hash ^= castlingHash(b.Castling)
+
+	b.EnPassant = nil
+	if piece.Kind == PAWN && (move.To.Rank-move.From.Rank == 2 || move.From.Rank-move.To.Rank == 2) {
+		b.EnPassant = &Position{File: move.From.File, Rank: (move.From.Rank + move.To.Rank) / 2}
+	}
+
+	b.passTurn()
+	// whether the square is hashed depends on the pawns of the side to move
+	b.hash ^= b.enPassantHash()
+	if piece.Kind == PAWN || undo.Captured != nil {
+		b.HalfTurns = 0
+	}
+
+	return undo
+}
+
+// isPawnBeside tells whether a pawn of the opponent of color stands on a file next to the position.
+func (b *Board) isPawnBeside(p Position, color Color) bool {
+	for _, file := range []File{p.File - 1, p.File + 1} {
+		if file < A || file > H {
+			continue
+		}
+		piece := b.PieceAt(Position{File: file, Rank: p.Rank})
+		if piece != nil && piece.Kind == PAWN && piece.Color != color {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (b *Board) passTurn() {
+	b.HalfTurns++
+	if b.Side == BLACK {
+		b.TurnNumber++
+	}
+	b.SwitchSide()
+}
+
+// UnmakeMove takes back the move recorded in undo. Moves have to be taken back in reverse order.
+func (b *Board) UnmakeMove(undo Undo) {
+	move := undo.Move
+
+	b.SwitchSide()
+	b.Castling = undo.Castling
+	b.EnPassant = undo.EnPassant
+	b.HalfTurns = undo.HalfTurns
+	b.TurnNumber = undo.TurnNumber
+	if move.IsNull() {
+		b.hash = undo.Hash
+		return
+	}
+
+	if castling, ok := castlingMoveFor(undo.Moved, move); ok {
+		rook := b.PieceAt(castling.RookTo)
+		b.ClearPieceAt(castling.RookTo)
+		b.SetPieceAt(castling.RookFrom, rook)
+	}
+
+	b.ClearPieceAt(move.To)
+	b.SetPieceAt(move.From, undo.Moved)
+	if undo.Captured != nil {
+		b.SetPieceAt(undo.CapturedAt, undo.Captured)
+	}
+	b.hash = undo.Hash
+}
+
+func castlingMoveFor(piece *Piece, move Move) (CastlingMove, bool) {
+	if piece.Kind != KING {
+		return CastlingMove{}, false
+	}
+	for _, castling := range CastlingMoves {
+		if castling.Side == piece.Color && castling.KingFrom == move.From && castling.KingTo == move.To {
+			return castling, true
+		}
+	}
+
+	return CastlingMove{}, false
+}
+
+// remainingCastling returns the castling rights left after the move. The slice stored in the board
+// is never modified, so an Undo can keep referencing it.
+func (b *Board) remainingCastling(piece *Piece, move Move) []Castling {
+	if len(b.Castling) == 0 {
+		return b.Castling
+	}
+
+	remaining := make([]Castling, 0, len(b.Castling))
+	for _, c := range b.Castling {
+		castling := CastlingMoves[c]
+		if piece.Kind == KING && piece.Color == castling.Side {
+			continue
+		}
+		if move.From == castling.RookFrom || move.To == castling.RookFrom {
+			continue
+		}
+		remaining = append(remaining, c)
+	}
+
+	return remaining
+}
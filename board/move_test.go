@@ -0,0 +1,210 @@
+package board_test
+
+import (
+	"chessBot/board"
+	"chessBot/fen"
+	"testing"
+)
+
+func TestMakeMove(t *testing.T) {
+	for _, testCase := range []struct {
+		desc       string
+		fen        string
+		move       string
+		promotion  board.ChessPieceKind
+		expected   string
+		side       board.Color
+		castling   []board.Castling
+		enPassant  *board.Position
+		halfTurns  int
+		turnNumber int
+	}{
+		{
+			desc:       "double pawn push",
+			fen:        fen.STARTPOSFEN,
+			move:       "e2e4",
+			expected:   "rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1",
+			side:       board.BLACK,
+			castling:   []board.Castling{board.WHITE_KINGSIDE, board.WHITE_QUEENSIDE, board.BLACK_KINGSIDE, board.BLACK_QUEENSIDE},
+			enPassant:  &board.Position{File: board.E, Rank: 3},
+			turnNumber: 1,
+		},
+		{
+			desc:       "black move increments turn number",
+			fen:        "rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1",
+			move:       "g8f6",
+			expected:   "rnbqkb1r/pppppppp/5n2/8/4P3/8/PPPP1PPP/RNBQKBNR w KQkq - 1 2",
+			side:       board.WHITE,
+			castling:   []board.Castling{board.WHITE_KINGSIDE, board.WHITE_QUEENSIDE, board.BLACK_KINGSIDE, board.BLACK_QUEENSIDE},
+			halfTurns:  1,
+			turnNumber: 2,
+		},
+		{
+			desc:       "kingside castling",
+			fen:        "r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 3 10",
+			move:       "e1g1",
+			expected:   "r3k2r/8/8/8/8/8/8/R4RK1 b kq - 4 10",
+			side:       board.BLACK,
+			castling:   []board.Castling{board.BLACK_KINGSIDE, board.BLACK_QUEENSIDE},
+			halfTurns:  4,
+			turnNumber: 10,
+		},
+		{
+			desc:       "queenside castling",
+			fen:        "r3k2r/8/8/8/8/8/8/R3K2R b KQkq - 3 10",
+			move:       "e8c8",
+			expected:   "2kr3r/8/8/8/8/8/8/R3K2R w KQ - 4 11",
+			side:       board.WHITE,
+			castling:   []board.Castling{board.WHITE_KINGSIDE, board.WHITE_QUEENSIDE},
+			halfTurns:  4,
+			turnNumber: 11,
+		},
+		{
+			desc:       "capturing a rook removes castling rights",
+			fen:        "r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 3 10",
+			move:       "a1a8",
+			expected:   "R3k2r/8/8/8/8/8/8/4K2R b Kk - 0 10",
+			side:       board.BLACK,
+			castling:   []board.Castling{board.WHITE_KINGSIDE, board.BLACK_KINGSIDE},
+			turnNumber: 10,
+		},
+		{
+			desc:       "en passant",
+			fen:        "4k3/8/8/3pP3/8/8/8/4K3 w - d6 0 1",
+			move:       "e5d6",
+			expected:   "4k3/8/3P4/8/8/8/8/4K3 b - - 0 1",
+			side:       board.BLACK,
+			turnNumber: 1,
+		},
+		{
+			desc:       "promotion",
+			fen:        "1n2k3/P7/8/8/8/8/8/4K3 w - - 5 40",
+			move:       "a7b8",
+			promotion:  board.QUEEN,
+			expected:   "1Q2k3/8/8/8/8/8/8/4K3 b - - 0 40",
+			side:       board.BLACK,
+			turnNumber: 40,
+		},
+	} {
+		b, err := fen.FenToBoard(testCase.fen)
+		if err != nil {
+			t.Fatal(err)
+		}
+		before := b.String()
+		move := board.MoveFromString(testCase.move)
+		move.Promotion = testCase.promotion
+
+		undo := b.MakeMove(move)
+
+		expected, err := fen.FenToBoard(testCase.expected)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if b.String() != expected.String() {
+			t.Errorf("%s: expected board\n%s\nbut got\n%s", testCase.desc, expected, b)
+		}
+		if b.Side != testCase.side {
+			t.Errorf("%s: expected side %d, but got %d", testCase.desc, testCase.side, b.Side)
+		}
+		if len(b.Castling) != len(testCase.castling) {
+			t.Errorf("%s: expected castling %v, but got %v", testCase.desc, testCase.castling, b.Castling)
+		}
+		for _, c := range testCase.castling {
+			if !b.IsCastlingPossible(c) {
+				t.Errorf("%s: expected castling %d to be possible", testCase.desc, c)
+			}
+		}
+		if !testCase.enPassant.SameAs(b.EnPassant) {
+			t.Errorf("%s: expected en passant %v, but got %v", testCase.desc, testCase.enPassant, b.EnPassant)
+		}
+		if b.HalfTurns != testCase.halfTurns {
+			t.Errorf("%s: expected %d half turns, but got %d", testCase.desc, testCase.halfTurns, b.HalfTurns)
+		}
+		if b.TurnNumber != testCase.turnNumber {
+			t.Errorf("%s: expected turn number %d, but got %d", testCase.desc, testCase.turnNumber, b.TurnNumber)
+		}
+
+		b.UnmakeMove(undo)
+
+		original, _ := fen.FenToBoard(testCase.fen)
+		if b.String() != before {
+			t.Errorf("%s: expected board to be restored to\n%s\nbut got\n%s", testCase.desc, before, b)
+		}
+		if b.Side != original.Side || len(b.Castling) != len(original.Castling) || !original.EnPassant.SameAs(b.EnPassant) ||
+			b.HalfTurns != original.HalfTurns || b.TurnNumber != original.TurnNumber {
+			t.Errorf("%s: expected state to be restored", testCase.desc)
+		}
+		for i, cell := range b.Cells {
+			if cell.Occupied != (cell.Occupant != nil) {
+				t.Errorf("%s: cell %d is inconsistent after unmaking the move", testCase.desc, i)
+			}
+		}
+	}
+}
+
+func TestMoveString(t *testing.T) {
+	for _, testCase := range []struct {
+		move     board.Move
+		expected string
+	}{
+		{
+			move:     board.Move{From: board.Position{File: board.E, Rank: 2}, To: board.Position{File: board.E, Rank: 4}},
+			expected: "e2e4",
+		},
+		{
+			move:     board.Move{From: board.Position{File: board.A, Rank: 7}, To: board.Position{File: board.A, Rank: 8}, Promotion: board.QUEEN},
+			expected: "a7a8q",
+		},
+		{
+			move:     board.Move{From: board.Position{File: board.B, Rank: 2}, To: board.Position{File: board.C, Rank: 1}, Promotion: board.KNIGHT, IsCapture: true, Captured: board.ROOK},
+			expected: "b2c1n",
+		},
+		{
+			move:     board.NullMove,
+			expected: "0000",
+		},
+	} {
+		if testCase.move.String() != testCase.expected {
+			t.Errorf("expected %s, but got %s", testCase.expected, testCase.move.String())
+		}
+	}
+}
+
+func TestParseMove(t *testing.T) {
+	for _, valid := range []string{"e2e4", "a7a8q", "h2h1n", "e1g1", "0000"} {
+		move, err := board.ParseMove(valid)
+		if err != nil {
+			t.Errorf("unexpected error parsing %s: %s", valid, err)
+			continue
+		}
+		if move.String() != valid {
+			t.Errorf("expected %s to round trip, but got %s", valid, move)
+		}
+	}
+
+	for _, invalid := range []string{"", "e2", "e2e", "e2e4qq", "i2e4", "e9e4", "e2e0", "a7a8k", "a7a8x"} {
+		_, err := board.ParseMove(invalid)
+		if err == nil {
+			t.Errorf("expected error parsing %q", invalid)
+		}
+	}
+
+	if !board.MoveFromString("e2").IsNull() {
+		t.Error("expected MoveFromString to return the null move for invalid input")
+	}
+}
+
+func TestMakeNullMove(t *testing.T) {
+	b, _ := fen.FenToBoard("rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1")
+	before := b.String()
+
+	undo := b.MakeMove(board.NullMove)
+	if b.Side != board.WHITE || b.EnPassant != nil || b.TurnNumber != 2 || b.String() != before {
+		t.Error("expected the null move to only pass the turn")
+	}
+
+	b.UnmakeMove(undo)
+	if b.Side != board.BLACK || b.EnPassant == nil || b.TurnNumber != 1 {
+		t.Error("expected the null move to be taken back")
+	}
+}
@@ -0,0 +1,101 @@
+package board
+
+// The zobrist keys are generated from a fixed seed, so a position has the same hash in every run.
+var zobristPieces [2][6][64]uint64
+var zobristSide uint64
+var zobristCastling [4]uint64
+var zobristEnPassant [8]uint64
+
+// zobristCastlingRights holds the castling hash of every Bitboards.Castling mask
+var zobristCastlingRights [16]uint64
+
+func init() {
+	// xorshift64*, good enough to spread the keys and without any dependency on math/rand's algorithm
+	state := uint64(0x9e3779b97f4a7c15)
+	next := func() uint64 {
+		state ^= state >> 12
+		state ^= state << 25
+		state ^= state >> 27
+		return state * 0x2545f4914f6cdd1d
+	}
+
+	for color := range zobristPieces {
+		for kind := range zobristPieces[color] {
+			for index := range zobristPieces[color][kind] {
+				zobristPieces[color][kind][index] = next()
+			}
+		}
+	}
+	zobristSide = next()
+	for i := range zobristCastling {
+		zobristCastling[i] = next()
+	}
+	for i := range zobristEnPassant {
+		zobristEnPassant[i] = next()
+	}
+
+	for rights := range zobristCastlingRights {
+		for c := range zobristCastling {
+			if rights&(1<<uint(c)) != 0 {
+				zobristCastlingRights[rights] ^= zobristCastling[c]
+			}
+		}
+	}
+}
+
+// Hash returns the zobrist key of the position: pieces, side to move, castling rights and the file
+// of the en passant square, if a pawn can capture on it. It is kept up to date by all methods
+// changing the board. Whoever sets Side, Castling or EnPassant directly has to call RefreshHash
+// afterwards.
+func (b *Board) Hash() uint64 {
+	return b.hash
+}
+
+// RefreshHash computes the hash from scratch.
+func (b *Board) RefreshHash() {
+	b.hash = b.ComputeHash()
+}
+
+// ComputeHash computes the hash from scratch without storing it.
+func (b *Board) ComputeHash() uint64 {
+	var hash uint64
+	for index, cell := range b.Cells {
+		if cell.Occupant != nil {
+			hash ^= zobristPieces[cell.Occupant.Color][cell.Occupant.Kind][index]
+		}
+	}
+	if b.Side == BLACK {
+		hash ^= zobristSide
+	}
+	hash ^= castlingHash(b.Castling)
+	hash ^= b.enPassantHash()
+
+	return hash
+}
+
+func castlingHash(castling []Castling) uint64 {
+	var hash uint64
+	for _, c := range castling {
+		hash ^= zobristCastling[c]
+	}
+
+	return hash
+}
+
+// enPassantHash hashes the en passant square only if a pawn of the side to move stands next to the
+// pawn that moved two squares. Otherwise the position is the same as the one without the square,
+// which matters for repetitions, as GUIs send the square after every double step.
+func (b *Board) enPassantHash() uint64 {
+	if b.EnPassant == nil {
+		return 0
+	}
+	moved := Position{File: b.EnPassant.File, Rank: b.EnPassant.Rank + 1}
+	if b.Side == WHITE {
+		moved.Rank = b.EnPassant.Rank - 1
+	}
+	if !b.isPawnBeside(moved, b.Side^1) {
+		return 0
+	}
+
+	return zobristEnPassant[b.EnPassant.File]
+}
@@ -0,0 +1,94 @@
+package board_test
+
+import (
+	"chessBot/board"
+	"chessBot/engine"
+	"chessBot/fen"
+	"math/rand"
+	"testing"
+)
+
+func TestHashIsIncremental(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+
+	for _, fenString := range []string{
+		fen.STARTPOSFEN,
+		"r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1",
+		"r3k2r/Pppp1ppp/1b3nbN/nP6/BBP1P3/q4N2/Pp1P2PP/R2Q1RK1 w kq - 0 1",
+	} {
+		for game := 0; game < 20; game++ {
+			e := engine.NewEngine(engine.Config{})
+			e.Board, _ = fen.FenToBoard(fenString)
+			initialHash := e.Board.Hash()
+			if initialHash != e.Board.ComputeHash() {
+				t.Fatalf("expected the hash of %s to be computed", fenString)
+			}
+
+			var undos []board.Undo
+			for ply := 0; ply < 150; ply++ {
+				moves := e.CalculatePossibleMoves(true)
+				if len(moves) == 0 {
+					break
+				}
+				move := moves[random.Intn(len(moves))]
+				if random.Intn(20) == 0 {
+					move = board.NullMove
+				}
+				undos = append(undos, e.Board.MakeMove(move))
+
+				if e.Board.Hash() != e.Board.ComputeHash() {
+					t.Fatalf("incremental hash differs from the computed one after %s in game %d from %s", move, game, fenString)
+				}
+			}
+
+			for i := len(undos) - 1; i >= 0; i-- {
+				e.Board.UnmakeMove(undos[i])
+			}
+			if e.Board.Hash() != initialHash {
+				t.Fatalf("expected the initial hash after taking back all moves in game %d from %s", game, fenString)
+			}
+		}
+	}
+}
+
+func TestHashOfTransposition(t *testing.T) {
+	play := func(moves ...string) *board.Board {
+		e := engine.NewEngine(engine.Config{})
+		b, _ := fen.FenToBoard(fen.STARTPOSFEN)
+		for _, moveString := range moves {
+			e.Board = b
+			for _, move := range e.CalculatePossibleMoves(true) {
+				if move.String() == moveString {
+					b.MakeMove(move)
+				}
+			}
+		}
+
+		return b
+	}
+
+	first := play("g1f3", "g8f6", "b1c3", "b8c6")
+	second := play("b1c3", "b8c6", "g1f3", "g8f6")
+	if first.Hash() != second.Hash() {
+		t.Error("expected the same hash for the same position")
+	}
+
+	// the same pieces, but different castling rights
+	third := play("g1f3", "g8f6", "h1g1", "h8g8", "g1h1", "g8h8")
+	start := play()
+	if third.Hash() == start.Hash() {
+		t.Error("expected castling rights to change the hash")
+	}
+
+	// the same pieces, but without en passant square
+	withEnPassant, _ := fen.FenToBoard("rnbqkbnr/ppp1pppp/8/8/3pP3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1")
+	withoutEnPassant, _ := fen.FenToBoard("rnbqkbnr/ppp1pppp/8/8/3pP3/8/PPPP1PPP/RNBQKBNR b KQkq - 0 1")
+	if withEnPassant.Hash() == withoutEnPassant.Hash() {
+		t.Error("expected the en passant square to change the hash")
+	}
+
+	// an en passant square no pawn can capture on is not recorded
+	if play("e2e4", "g8f6", "g1f3", "f6g8", "f3g1").Hash() != play("e2e4").Hash() {
+		t.Error("expected the same hash after the knights returned")
+	}
+}
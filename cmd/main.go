@@ -1,56 +1,25 @@
 package main
 
 import (
-	"bufio"
 	"chessBot/engine"
-	"chessBot/uci"
-	"fmt"
-	"math/rand"
+	"log"
 	"os"
 )
 
 func main() {
+	logOutput, err := os.Create("outstandingMove.log")
+	if err != nil {
+		log.Fatal("error opening log file:", err)
+	}
+	defer logOutput.Close()
 
+	e := engine.NewEngine(engine.Config{
+		Output:    os.Stdout,
+		LogOutput: logOutput,
+	})
 
-	engine.Log("Welcome to Outstanding Move! Waiting for commands...\n")
-
-	reader := bufio.NewReader(os.Stdin)
-
-	for {
-		text, _ := reader.ReadString('\n')
-		engine.Log(text)
-		stmnts, err := uci.Parse(text)
-		if err != nil {
-			engine.Log("Error when parsing input:\n")
-			engine.Log(err.Error())
-			continue
-		}
-
-		for _, stmnt := range stmnts {
-			engine.Log("<- " + string(stmnt.Kind))
-			switch stmnt.Kind {
-			case uci.UciStatementKind:
-				engine.Send("uciok")
-			case uci.IsReadyStatementKind:
-				engine.Send("readyok")
-			case uci.UciNewGameStatementKind:
-			case uci.PositionStatementKind:
-				engine.Log(fmt.Sprintf("%+v", stmnt.Position))
-				err = engine.InitBoard(stmnt.Position)
-				if err != nil {
-					engine.Log("error initializing board: " + err.Error())
-				}
-				engine.Log("Current Board:")
-				engine.Log(engine.CurrentBoard.String())
-				engine.CurrentBoard.SwitchSide() // todo: do that on engine level.
-			case uci.GoStatementKind:
-				moves := engine.CalculatePossibleMoves(true)
-				move := moves[rand.Int() % len(moves)]
-				engine.Send("bestmove " + move.String())
-
-			}
-		}
+	err = e.Run(os.Stdin)
+	if err != nil {
+		e.Log("error reading input: " + err.Error())
 	}
-
 }
-
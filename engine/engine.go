@@ -5,248 +5,204 @@ import (
 	"chessBot/fen"
 	"chessBot/uci"
 	"fmt"
-	"log"
-	"os"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"sync"
 )
 
-var CurrentBoard *board.Board
-var LogOutput *os.File
-var mb120 *board.Mailbox120
-var mb64 *board.Mailbox64
-
-func init() {
-	var err error
-	LogOutput, err = os.Create("outstandingMove.log")
-	if err != nil {
-		log.Fatal("error opening log file:", err)
-	}
-
-	mb120 = board.NewMailbox120()
-	mb64 = board.NewMailbox64()
-}
+const (
+	Name   = "Outstanding Move"
+	Author = "the Outstanding Move authors"
+)
 
-func Send(msg string) {
-	fmt.Println(msg)
-	Log(fmt.Sprintf("-> %s", msg))
+// Config holds everything an engine needs from its environment.
+type Config struct {
+	// Output receives the messages to the GUI. They are dropped if it is nil.
+	Output io.Writer
+	// LogOutput receives the log. Nothing is logged if it is nil.
+	LogOutput io.Writer
+	// Clock is used to measure the thinking time. The system clock is used if it is nil.
+	Clock Clock
+	// HashSize is the size of the transposition table in megabytes, DefaultHashSize if it is 0.
+	HashSize int
 }
 
-func Log(msg string) {
-	LogOutput.WriteString(msg + "\n")
-	LogOutput.Sync()
+// Engine plays one game at a time. Several engines can be used concurrently.
+type Engine struct {
+	Board *board.Board
+	// history holds the hashes of the positions before the board, to recognize repetitions
+	history []uint64
+
+	config Config
+	mutex  sync.Mutex
+	tt     *TranspositionTable
+	// options are advertised on uci and changed with setoption
+	options *Options
+	// debug is set by the GUI, diagnostics are sent as info strings then
+	debug bool
+
+	// the signals of the search running in the background, nil if there is none
+	searchMutex sync.Mutex
+	stop        chan struct{}
+	ponderhit   chan struct{}
+	done        chan struct{}
 }
 
-func InitBoard(stmnt *uci.PositionStatement) error {
-	initString := fen.STARTPOSSTRING
-
-	if stmnt.IsFen {
-		initString = stmnt.FenString
+func NewEngine(config Config) *Engine {
+	if config.Output == nil {
+		config.Output = ioutil.Discard
 	}
-	var err error
-	CurrentBoard, err = fen.FenToBoard(initString)
-	if err != nil {
-		return err
+	if config.LogOutput == nil {
+		config.LogOutput = ioutil.Discard
 	}
-
-	for _, moveString := range stmnt.Moves {
-		Log("moving " + moveString)
-		move := board.MoveFromString(moveString)
-		CurrentBoard.Move(move)
+	if config.Clock == nil {
+		config.Clock = systemClock{}
 	}
-
-	return nil
-}
-
-func CalculatePossibleMoves(filterMoves bool) []board.Move {
-	var moves []board.Move
-	posInMb64 := -1
-	for rank := 1; rank < 9; rank++ {
-		for _, file := range board.AllFiles {
-			posInMb64++
-			position := board.Position{file, rank}
-			piece := CurrentBoard.PieceAt(position)
-			if piece == nil {
-				continue
-			}
-			if piece.Color != CurrentBoard.Side {
-				continue
-			}
-
-			newMoves := calculateMovesForPieceAt(piece, posInMb64)
-
-			moves = append(moves, newMoves...)
-		}
+	if config.HashSize == 0 {
+		config.HashSize = DefaultHashSize
+	}
+	// the size is advertised as the default of the Hash option, so it has to be within its bounds
+	if config.HashSize < MinHashSize {
+		config.HashSize = MinHashSize
+	}
+	if config.HashSize > MaxHashSize {
+		config.HashSize = MaxHashSize
 	}
 
-	if filterMoves {
-		moves = filterMovesIntoCheck(moves)
+	e := &Engine{
+		config:  config,
+		tt:      NewTranspositionTable(config.HashSize),
+		options: NewOptions(),
 	}
+	e.addOptions()
 
-	return moves
+	return e
 }
 
-func kingPositionOfCurrentPlayer() board.Position {
-	var kingPosition board.Position
-	for rank := 1; rank < 9; rank++ {
-		for _, file := range board.AllFiles {
-			kingPosition = board.Position{file, rank}
-			piece := CurrentBoard.PieceAt(kingPosition)
-			if piece == nil {
-				continue
-			}
-			if piece.Color != CurrentBoard.Side {
-				continue
-			}
-			if piece.Kind != board.KING {
-				continue
-			}
-			return kingPosition
+// addOptions registers the options of the engine itself.
+func (e *Engine) addOptions() {
+	for _, option := range []*Option{
+		{
+			Name: "Hash", Type: uci.SPIN, Default: strconv.Itoa(e.config.HashSize), Min: MinHashSize, Max: MaxHashSize,
+			OnChange: func(value string) {
+				size, _ := strconv.Atoi(value)
+				e.tt.Resize(size)
+			},
+		},
+		{
+			Name: "Clear Hash", Type: uci.BUTTON,
+			OnChange: func(string) {
+				e.tt.Clear()
+			},
+		},
+	} {
+		if err := e.options.Add(option); err != nil {
+			panic(err)
 		}
 	}
+}
 
-	return kingPosition // todo: this should not be reached. Return err
+// Options returns the options of the engine, to which more can be added before it runs.
+func (e *Engine) Options() *Options {
+	return e.options
 }
 
-func filterMovesIntoCheck(moves []board.Move) []board.Move {
-	var validMoves []board.Move
-	var eliminatedMoves []board.Move
+func (e *Engine) Send(msg string) {
+	e.mutex.Lock()
+	fmt.Fprintln(e.config.Output, msg)
+	e.mutex.Unlock()
 
-	kingPosition := kingPositionOfCurrentPlayer()
+	e.Log(fmt.Sprintf("-> %s", msg))
+}
 
-	friendSide := CurrentBoard.Side
-	enemySide := board.WHITE
-	if friendSide == board.WHITE {
-		enemySide = board.BLACK
-	}
+// SendMessage sends a typed message to the GUI.
+func (e *Engine) SendMessage(msg *uci.Message) {
+	e.Send(msg.String())
+}
 
-	// setup board for enemy moves
-	CurrentBoard.Side = enemySide
+// SetDebug turns the debug mode on or off.
+func (e *Engine) SetDebug(on bool) {
+	e.mutex.Lock()
+	e.debug = on
+	e.mutex.Unlock()
+}
 
-	for _, move := range moves {
-		currentKingPosition := kingPosition
-		if CurrentBoard.PieceAt(move.From).Kind == board.KING {
-			currentKingPosition = move.To
-		}
-		foundKing := false
-		originalPieceAtTarget := CurrentBoard.PieceAt(move.To)
-		CurrentBoard.Move(move)
-
-		allPossibleEnemyMoves := CalculatePossibleMoves(false)
-		for _, enemyMove := range allPossibleEnemyMoves {
-			if enemyMove.To == currentKingPosition {
-				foundKing = true
-			}
-		}
-		if !foundKing {
-			validMoves = append(validMoves, move)
-		} else {
-			eliminatedMoves = append(eliminatedMoves, move)
-		}
+// Debug logs a diagnostic. In debug mode it is sent to the GUI as an info string instead, which
+// ends up in the log as well.
+func (e *Engine) Debug(msg string) {
+	e.mutex.Lock()
+	debug := e.debug
+	e.mutex.Unlock()
 
-		CurrentBoard.Move(move.Invert())
-		CurrentBoard.SetPieceAt(move.To, originalPieceAtTarget)
+	if !debug {
+		e.Log(msg)
+		return
 	}
-
-	// reset board
-	CurrentBoard.Side = friendSide
-
-	return validMoves
+	// the string must not end the line early
+	text := strings.Join(strings.Fields(msg), " ")
+	e.SendMessage(&uci.Message{Kind: uci.InfoMessageKind, Info: &uci.InfoMessage{Kinds: []uci.InfoKind{uci.Info_stringKind}, Text: text}})
 }
 
-func calculateMovesForPieceAt(piece *board.Piece, posInMb64 int) []board.Move {
-	var moves []board.Move
-	from := board.PositionFromIndex(posInMb64)
+func (e *Engine) Log(msg string) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
 
-	if piece.Kind == board.PAWN {
-		return movesForPawn(piece, posInMb64)
+	io.WriteString(e.config.LogOutput, msg+"\n")
+	if syncer, ok := e.config.LogOutput.(interface{ Sync() error }); ok {
+		syncer.Sync()
 	}
-
-	for j := 0; j < piece.Directions; j++ {
-		n := posInMb64
-		for {
-			n = mb120[mb64[n]+piece.Offsets[j]]
-			if n == -1 {
-				break
-			}
-			to := board.PositionFromIndex(n)
-			targetPiece := CurrentBoard.PieceAt(*to)
-			if targetPiece == nil { // todo: avoid moving into chess with the king
-				moves = append(moves, board.Move{
-					From: *from,
-					To:   *to,
-				})
-				if !piece.Slide {
-					break
-				}
-				continue
-			}
-			if targetPiece.Color != CurrentBoard.Side {
-				moves = append(moves, board.Move{
-					From: *from,
-					To:   *to,
-				})
-			}
-			break
-		}
-	}
-
-	return moves
 }
 
-func movesForPawn(piece *board.Piece, posInMb64 int) []board.Move {
-	var moves []board.Move
-	var moveOffsets []int
-	var strikeOffsets []int
-	from := board.PositionFromIndex(posInMb64)
-	if piece.Color == board.WHITE {
-		moveOffsets = append(moveOffsets, 10)
-		if from.Rank == 2 {
-			moveOffsets = append(moveOffsets, 20)
-		}
-		strikeOffsets = []int{9, 11}
+// InitBoard sets up the position of the statement. If a move is illegal, the position is kept up to
+// the last legal move, like other engines do. If the fen cannot be read, the board is left empty,
+// so that no move is found instead of searching the previous game.
+func (e *Engine) InitBoard(stmnt *uci.PositionStatement) error {
+	initString := fen.STARTPOSSTRING
+
+	if stmnt.IsFen {
+		initString = stmnt.FenString
 	}
-	if piece.Color == board.BLACK {
-		moveOffsets = append(moveOffsets, -10)
-		if from.Rank == 7 {
-			moveOffsets = append(moveOffsets, -20)
-		}
-		strikeOffsets = []int{-9, -11}
+	b, err := fen.FenToBoard(initString)
+	if err != nil {
+		e.Board = board.NewBoard()
+		e.history = nil
+		return err
 	}
 
-	// moves
-	for _, offset := range moveOffsets {
-		newPosInt := mb120[mb64[posInMb64]+offset]
-		if newPosInt == -1 {
-			continue // todo: Handle promotion
+	e.Board = b
+	e.history = nil
+	for _, moveString := range stmnt.Moves {
+		e.Log("moving " + moveString)
+		move, err := board.ParseMove(moveString)
+		if err != nil {
+			return err
 		}
-		to := board.PositionFromIndex(newPosInt)
-		pieceAtNewPos := CurrentBoard.PieceAt(*to)
-		if pieceAtNewPos != nil {
-			break
+		legalMove, ok := findLegalMove(b, move)
+		if !ok {
+			return fmt.Errorf("illegal move %s", moveString)
 		}
-		moves = append(moves, board.Move{
-			From: *from,
-			To:   *to,
-		})
+		e.history = append(e.history, b.Hash())
+		b.MakeMove(legalMove)
 	}
 
-	// strikes
-	for _, offset := range strikeOffsets {
-		newPosInt := mb120[mb64[posInMb64]+offset]
-		if newPosInt == -1 {
-			continue
-		}
-		to := board.PositionFromIndex(newPosInt)
-		pieceAtNewPos := CurrentBoard.PieceAt(*to)
-		if pieceAtNewPos == nil {
-			continue
-		}
-		if pieceAtNewPos.Color != CurrentBoard.Side {
-			moves = append(moves, board.Move{
-				From: *from,
-				To:   *to,
-			})
+	return nil
+}
+
+// CalculatePossibleMoves returns the moves of the side to move. Unless filterMoves is set, this
+// includes moves leaving the own king in check.
+func (e *Engine) CalculatePossibleMoves(filterMoves bool) []board.Move {
+	return generateMoves(e.Board, filterMoves)
+}
+
+// findLegalMove returns the legal move going the same way as move, with all its flags set.
+func findLegalMove(b *board.Board, move board.Move) (board.Move, bool) {
+	for _, legalMove := range generateMoves(b, true) {
+		if legalMove.SameAs(move) {
+			return legalMove, true
 		}
 	}
 
-	return moves
+	return board.Move{}, false
 }
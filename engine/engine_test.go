@@ -1,15 +1,19 @@
 package engine
 
 import (
+	"bytes"
 	"chessBot/board"
 	"chessBot/fen"
+	"chessBot/uci"
 	"fmt"
+	"strings"
+	"sync"
 	"testing"
 )
 
 func TestCalculatePossibleMoves(t *testing.T) {
-	CurrentBoard, _ = fen.FenToBoard(fen.STARTPOSFEN)
-	moves := CalculatePossibleMoves(true)
+	e := engineWithFen(fen.STARTPOSFEN)
+	moves := e.CalculatePossibleMoves(true)
 
 	if len(moves) != 20 {
 		t.Error("Expected 20 possible moves, but got", len(moves))
@@ -18,9 +22,9 @@ func TestCalculatePossibleMoves(t *testing.T) {
 }
 
 func TestCalculatePossibleMovesForBlack(t *testing.T) {
-	CurrentBoard, _ = fen.FenToBoard(fen.STARTPOSFEN)
-	CurrentBoard.Side = board.BLACK
-	moves := CalculatePossibleMoves(true)
+	e := engineWithFen(fen.STARTPOSFEN)
+	e.Board.Side = board.BLACK
+	moves := e.CalculatePossibleMoves(true)
 
 	if len(moves) != 20 {
 		t.Error("Expected 20 possible moves, but got", len(moves))
@@ -29,9 +33,9 @@ func TestCalculatePossibleMovesForBlack(t *testing.T) {
 }
 
 func TestCalculatePossibleMovesNoMoveToChess(t *testing.T) {
-	CurrentBoard, _ = fen.FenToBoard("4k3/8/7b/7b/8/8/4P3/3K4 w - - 0 1")
+	e := engineWithFen("4k3/8/7b/7b/8/8/4P3/3K4 w - - 0 1")
 
-	moves := CalculatePossibleMoves(true)
+	moves := e.CalculatePossibleMoves(true)
 
 	if len(moves) != 2 {
 		t.Error("Expected 2 possible moves, but got", len(moves))
@@ -40,12 +44,206 @@ func TestCalculatePossibleMovesNoMoveToChess(t *testing.T) {
 }
 
 func TestMoveOutOfChess(t *testing.T) {
-	CurrentBoard, _ = fen.FenToBoard("1r2qbnr/3b1k1p/Pp3pB1/1NPp4/5NP1/4P2Q/PBP4P/R3K1R1 b Q - 0 20")
+	e := engineWithFen("1r2qbnr/3b1k1p/Pp3pB1/1NPp4/5NP1/4P2Q/PBP4P/R3K1R1 b Q - 0 20")
 
-	moves := CalculatePossibleMoves(true)
+	moves := e.CalculatePossibleMoves(true)
 
 	if len(moves) != 3 {
 		t.Error("Expected 3 possible moves, but got", len(moves))
 		fmt.Print(moves)
 	}
-}
\ No newline at end of file
+}
+
+func TestCastling(t *testing.T) {
+	for _, testCase := range []struct {
+		fen      string
+		expected []string
+		excluded []string
+	}{
+		{
+			fen:      "r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1",
+			expected: []string{"e1g1", "e1c1"},
+		},
+		{
+			fen:      "r3k2r/8/8/8/8/8/8/R3K2R b KQkq - 0 1",
+			expected: []string{"e8g8", "e8c8"},
+		},
+		{
+			fen:      "r3k2r/8/8/8/8/8/8/R3K2R w Qk - 0 1",
+			expected: []string{"e1c1"},
+			excluded: []string{"e1g1"},
+		},
+		{
+			fen:      "4kr2/8/8/8/8/8/8/R3K2R w KQ - 0 1",
+			expected: []string{"e1c1"},
+			excluded: []string{"e1g1"},
+		},
+		{
+			fen:      "4k3/8/8/8/8/8/8/RN2K2R w KQ - 0 1",
+			expected: []string{"e1g1"},
+			excluded: []string{"e1c1"},
+		},
+		{
+			fen:      "4k3/8/8/8/8/8/8/R3K2r w Q - 0 1",
+			excluded: []string{"e1c1", "e1g1"},
+		},
+	} {
+		e := engineWithFen(testCase.fen)
+		moves := e.CalculatePossibleMoves(true)
+
+		for _, expected := range testCase.expected {
+			if !containsMove(moves, expected) {
+				t.Errorf("expected %s to be possible in %s, but got %v", expected, testCase.fen, moves)
+			}
+		}
+		for _, excluded := range testCase.excluded {
+			if containsMove(moves, excluded) {
+				t.Errorf("expected %s not to be possible in %s", excluded, testCase.fen)
+			}
+		}
+	}
+}
+
+func TestEnPassant(t *testing.T) {
+	e := engineWithFen("4k3/8/8/3pP3/8/8/8/4K3 w - d6 0 1")
+	moves := e.CalculatePossibleMoves(true)
+	if !containsMove(moves, "e5d6") {
+		t.Error("expected en passant capture e5d6, but got", moves)
+	}
+
+	e = engineWithFen("4k3/8/8/3pP3/8/8/8/4K3 w - - 0 1")
+	moves = e.CalculatePossibleMoves(true)
+	if containsMove(moves, "e5d6") {
+		t.Error("expected no en passant capture without en passant square")
+	}
+
+	// capturing would expose the king to the rook on the fifth rank
+	e = engineWithFen("8/8/8/K2pP2r/8/8/8/4k3 w - d6 0 1")
+	moves = e.CalculatePossibleMoves(true)
+	if containsMove(moves, "e5d6") {
+		t.Error("expected en passant capture e5d6 to be illegal")
+	}
+}
+
+func TestPromotion(t *testing.T) {
+	e := engineWithFen("1n2k3/P7/8/8/8/8/8/4K3 w - - 0 1")
+	moves := e.CalculatePossibleMoves(true)
+
+	for _, expected := range []string{"a7a8q", "a7a8r", "a7a8b", "a7a8n", "a7b8q", "a7b8r", "a7b8b", "a7b8n"} {
+		if !containsMove(moves, expected) {
+			t.Errorf("expected %s to be possible, but got %v", expected, moves)
+		}
+	}
+	if containsMove(moves, "a7a8") {
+		t.Error("expected pawn to promote")
+	}
+}
+
+func engineWithFen(fenString string) *Engine {
+	b, err := fen.FenToBoard(fenString)
+	if err != nil {
+		panic(err)
+	}
+	e := NewEngine(Config{})
+	e.Board = b
+
+	return e
+}
+
+func containsMove(moves []board.Move, moveString string) bool {
+	for _, move := range moves {
+		if move.String() == moveString {
+			return true
+		}
+	}
+
+	return false
+}
+
+func TestMoveFlags(t *testing.T) {
+	e := engineWithFen("r3k2r/8/8/3pP3/8/8/P7/R3K2R w KQkq d6 0 1")
+	moves := e.CalculatePossibleMoves(true)
+
+	for _, move := range moves {
+		switch move.String() {
+		case "e1g1", "e1c1":
+			if !move.IsCastling {
+				t.Errorf("expected %s to be castling", move)
+			}
+		case "e5d6":
+			if !move.IsEnPassant || !move.IsCapture || move.Captured != board.PAWN {
+				t.Errorf("expected %s to be an en passant capture", move)
+			}
+		case "a2a4":
+			if !move.IsDoublePawnPush {
+				t.Errorf("expected %s to be a double pawn push", move)
+			}
+		case "a1a8":
+			if !move.IsCapture || move.Captured != board.ROOK {
+				t.Errorf("expected %s to capture a rook", move)
+			}
+		case "a2a3":
+			if move.IsCapture || move.IsCastling || move.IsEnPassant || move.IsDoublePawnPush {
+				t.Errorf("expected %s to be a quiet move", move)
+			}
+		}
+	}
+}
+
+func TestInitBoard(t *testing.T) {
+	e := NewEngine(Config{})
+	err := e.InitBoard(&uci.PositionStatement{
+		IsStartPos: true,
+		Moves:      []string{"e2e4", "e7e5", "g1f3"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected, _ := fen.FenToBoard("rnbqkbnr/pppp1ppp/8/4p3/4P3/5N2/PPPP1PPP/RNBQKB1R b KQkq - 1 2")
+	if e.Board.String() != expected.String() || e.Board.Side != board.BLACK {
+		t.Errorf("expected board\n%s\nbut got\n%s", expected, e.Board)
+	}
+
+	err = e.InitBoard(&uci.PositionStatement{
+		IsStartPos: true,
+		Moves:      []string{"e2e5"},
+	})
+	if err == nil {
+		t.Error("expected an error for an illegal move")
+	}
+}
+
+func TestEnginesAreIndependent(t *testing.T) {
+	var logs [4]bytes.Buffer
+	var wg sync.WaitGroup
+	results := make([]int, len(logs))
+	games := [][]string{{}, {"e2e4"}, {"e2e4", "e7e5"}, {"d2d4", "d7d5", "c2c4"}}
+
+	for i := range logs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			e := NewEngine(Config{LogOutput: &logs[i]})
+			err := e.InitBoard(&uci.PositionStatement{IsStartPos: true, Moves: games[i]})
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			results[i] = Perft(e.Board, 3)
+		}(i)
+	}
+	wg.Wait()
+
+	for i, game := range games {
+		e := NewEngine(Config{})
+		e.InitBoard(&uci.PositionStatement{IsStartPos: true, Moves: game})
+		expected := Perft(e.Board, 3)
+		if results[i] != expected {
+			t.Errorf("engine %d: expected %d nodes, but got %d", i, expected, results[i])
+		}
+		if strings.Count(logs[i].String(), "moving") != len(game) {
+			t.Errorf("engine %d: expected only its own moves in its log, but got %q", i, logs[i].String())
+		}
+	}
+}
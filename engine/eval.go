@@ -0,0 +1,338 @@
+package engine
+
+import (
+	"chessBot/board"
+	"fmt"
+	"strings"
+)
+
+// Term names a part of the evaluation.
+type Term int
+
+const (
+	MATERIAL Term = iota
+	PIECE_SQUARES
+	BISHOP_PAIR
+	ROOK_OPEN_FILE
+	PASSED_PAWNS
+)
+
+var AllTerms = []Term{MATERIAL, PIECE_SQUARES, BISHOP_PAIR, ROOK_OPEN_FILE, PASSED_PAWNS}
+
+func (t Term) String() string {
+	switch t {
+	case MATERIAL:
+		return "material"
+	case PIECE_SQUARES:
+		return "piece squares"
+	case BISHOP_PAIR:
+		return "bishop pair"
+	case ROOK_OPEN_FILE:
+		return "rook open file"
+	case PASSED_PAWNS:
+		return "passed pawns"
+	}
+
+	return "unknown"
+}
+
+// TermScore is the value of one term in centipawns from white's point of view, once for the
+// middlegame and once for the endgame.
+type TermScore struct {
+	Middlegame int
+	Endgame    int
+}
+
+// Evaluation explains how Evaluate came to its score.
+type Evaluation struct {
+	Terms [PASSED_PAWNS + 1]TermScore
+	// Phase goes from maxPhase with all pieces on the board down to 0 when only kings and pawns are left.
+	Phase int
+	// Score is the tapered sum of all terms from the point of view of the side to move.
+	Score int
+}
+
+func (ev Evaluation) String() string {
+	var out strings.Builder
+	fmt.Fprintf(&out, "%-16s %6s %6s\n", "term", "mg", "eg")
+	for _, term := range AllTerms {
+		fmt.Fprintf(&out, "%-16s %6d %6d\n", term, ev.Terms[term].Middlegame, ev.Terms[term].Endgame)
+	}
+	fmt.Fprintf(&out, "phase %d/%d, score %d\n", ev.Phase, maxPhase, ev.Score)
+
+	return out.String()
+}
+
+const maxPhase = 24
+
+var phaseWeights = [6]int{0, 1, 1, 2, 4, 0}
+
+var middlegameValues = [6]int{100, 320, 330, 500, 900, 0}
+var endgameValues = [6]int{120, 300, 320, 530, 950, 0}
+
+var bishopPair = TermScore{30, 50}
+var rookOpenFile = TermScore{25, 10}
+var rookSemiOpenFile = TermScore{12, 5}
+
+// indexed by the rank seen from the pawn's side, so 2 is the rank the pawn starts on
+var passedPawnMiddlegame = [9]int{0, 0, 5, 10, 15, 25, 40, 60, 0}
+var passedPawnEndgame = [9]int{0, 0, 10, 20, 35, 60, 100, 150, 0}
+
+// passedPawnMasks holds the squares in front of a pawn on its own and the neighbouring files, a
+// pawn is passed if there is no enemy pawn on them.
+var passedPawnMasks [2][64]board.Bitboard
+
+func init() {
+	for index := 0; index < 64; index++ {
+		file, rank := index&7, index>>3
+		for f := file - 1; f <= file+1; f++ {
+			if f < 0 || f > 7 {
+				continue
+			}
+			for r := 0; r < 8; r++ {
+				if r > rank {
+					passedPawnMasks[board.WHITE][index] |= board.SquareBitboard(r*8 + f)
+				}
+				if r < rank {
+					passedPawnMasks[board.BLACK][index] |= board.SquareBitboard(r*8 + f)
+				}
+			}
+		}
+	}
+}
+
+// Evaluate scores the position in centipawns from the point of view of the side to move.
+func Evaluate(b *board.Board) int {
+	return evaluate(board.NewBitboards(b))
+}
+
+func evaluate(bb *board.Bitboards) int {
+	return explainEvaluation(bb).Score
+}
+
+// ExplainEvaluation returns every term Evaluate takes into account.
+func ExplainEvaluation(b *board.Board) Evaluation {
+	return explainEvaluation(board.NewBitboards(b))
+}
+
+func explainEvaluation(bb *board.Bitboards) Evaluation {
+	ev := Evaluation{}
+
+	for _, color := range []board.Color{board.WHITE, board.BLACK} {
+		sign := signOf(color)
+		enemy := opponentOf(color)
+
+		for kind, pieces := range bb.Pieces[color] {
+			for pieces != 0 {
+				index := pieces.Pop()
+				ev.add(MATERIAL, sign, middlegameValues[kind], endgameValues[kind])
+				tableIndex := index
+				if color == board.WHITE {
+					// the tables show the board from white's side, with the eighth rank first
+					tableIndex ^= 56
+				}
+				ev.add(PIECE_SQUARES, sign, middlegameTables[kind][tableIndex], endgameTables[kind][tableIndex])
+
+				ev.Phase += phaseWeights[kind]
+			}
+		}
+
+		if bb.Pieces[color][board.BISHOP].Count() >= 2 {
+			ev.add(BISHOP_PAIR, sign, bishopPair.Middlegame, bishopPair.Endgame)
+		}
+
+		rooks := bb.Pieces[color][board.ROOK]
+		for rooks != 0 {
+			file := board.FileA << uint(rooks.Pop()&7)
+			switch {
+			case bb.Pieces[color][board.PAWN]&file != 0:
+			case bb.Pieces[enemy][board.PAWN]&file != 0:
+				ev.add(ROOK_OPEN_FILE, sign, rookSemiOpenFile.Middlegame, rookSemiOpenFile.Endgame)
+			default:
+				ev.add(ROOK_OPEN_FILE, sign, rookOpenFile.Middlegame, rookOpenFile.Endgame)
+			}
+		}
+
+		pawns := bb.Pieces[color][board.PAWN]
+		for pawns != 0 {
+			index := pawns.Pop()
+			if passedPawnMasks[color][index]&bb.Pieces[enemy][board.PAWN] != 0 {
+				continue
+			}
+			// the rank seen from the pawn's side
+			rank := index>>3 + 1
+			if color == board.BLACK {
+				rank = 9 - rank
+			}
+			ev.add(PASSED_PAWNS, sign, passedPawnMiddlegame[rank], passedPawnEndgame[rank])
+		}
+	}
+
+	phase := ev.Phase
+	if phase > maxPhase {
+		phase = maxPhase
+	}
+	middlegame, endgame := 0, 0
+	for _, term := range ev.Terms {
+		middlegame += term.Middlegame
+		endgame += term.Endgame
+	}
+	ev.Score = (middlegame*phase + endgame*(maxPhase-phase)) / maxPhase
+	if bb.Side == board.BLACK {
+		ev.Score = -ev.Score
+	}
+
+	return ev
+}
+
+func (ev *Evaluation) add(term Term, sign int, middlegame int, endgame int) {
+	ev.Terms[term].Middlegame += sign * middlegame
+	ev.Terms[term].Endgame += sign * endgame
+}
+
+func signOf(color board.Color) int {
+	if color == board.WHITE {
+		return 1
+	}
+
+	return -1
+}
+
+// The piece square tables are seen from white's side: the first row is the eighth rank.
+var middlegameTables = [6][64]int{
+	// pawn
+	{
+		0, 0, 0, 0, 0, 0, 0, 0,
+		50, 50, 50, 50, 50, 50, 50, 50,
+		10, 10, 20, 30, 30, 20, 10, 10,
+		5, 5, 10, 25, 25, 10, 5, 5,
+		0, 0, 0, 20, 20, 0, 0, 0,
+		5, -5, -10, 0, 0, -10, -5, 5,
+		5, 10, 10, -20, -20, 10, 10, 5,
+		0, 0, 0, 0, 0, 0, 0, 0,
+	},
+	// knight
+	{
+		-50, -40, -30, -30, -30, -30, -40, -50,
+		-40, -20, 0, 0, 0, 0, -20, -40,
+		-30, 0, 10, 15, 15, 10, 0, -30,
+		-30, 5, 15, 20, 20, 15, 5, -30,
+		-30, 0, 15, 20, 20, 15, 0, -30,
+		-30, 5, 10, 15, 15, 10, 5, -30,
+		-40, -20, 0, 5, 5, 0, -20, -40,
+		-50, -40, -30, -30, -30, -30, -40, -50,
+	},
+	// bishop
+	{
+		-20, -10, -10, -10, -10, -10, -10, -20,
+		-10, 0, 0, 0, 0, 0, 0, -10,
+		-10, 0, 5, 10, 10, 5, 0, -10,
+		-10, 5, 5, 10, 10, 5, 5, -10,
+		-10, 0, 10, 10, 10, 10, 0, -10,
+		-10, 10, 10, 10, 10, 10, 10, -10,
+		-10, 5, 0, 0, 0, 0, 5, -10,
+		-20, -10, -10, -10, -10, -10, -10, -20,
+	},
+	// rook
+	{
+		0, 0, 0, 0, 0, 0, 0, 0,
+		5, 10, 10, 10, 10, 10, 10, 5,
+		-5, 0, 0, 0, 0, 0, 0, -5,
+		-5, 0, 0, 0, 0, 0, 0, -5,
+		-5, 0, 0, 0, 0, 0, 0, -5,
+		-5, 0, 0, 0, 0, 0, 0, -5,
+		-5, 0, 0, 0, 0, 0, 0, -5,
+		0, 0, 0, 5, 5, 0, 0, 0,
+	},
+	// queen
+	{
+		-20, -10, -10, -5, -5, -10, -10, -20,
+		-10, 0, 0, 0, 0, 0, 0, -10,
+		-10, 0, 5, 5, 5, 5, 0, -10,
+		-5, 0, 5, 5, 5, 5, 0, -5,
+		0, 0, 5, 5, 5, 5, 0, -5,
+		-10, 5, 5, 5, 5, 5, 0, -10,
+		-10, 0, 5, 0, 0, 0, 0, -10,
+		-20, -10, -10, -5, -5, -10, -10, -20,
+	},
+	// king
+	{
+		-30, -40, -40, -50, -50, -40, -40, -30,
+		-30, -40, -40, -50, -50, -40, -40, -30,
+		-30, -40, -40, -50, -50, -40, -40, -30,
+		-30, -40, -40, -50, -50, -40, -40, -30,
+		-20, -30, -30, -40, -40, -30, -30, -20,
+		-10, -20, -20, -20, -20, -20, -20, -10,
+		20, 20, 0, 0, 0, 0, 20, 20,
+		20, 30, 10, 0, 0, 10, 30, 20,
+	},
+}
+
+var endgameTables = [6][64]int{
+	// pawn
+	{
+		0, 0, 0, 0, 0, 0, 0, 0,
+		80, 80, 80, 80, 80, 80, 80, 80,
+		50, 50, 50, 50, 50, 50, 50, 50,
+		30, 30, 30, 30, 30, 30, 30, 30,
+		15, 15, 15, 15, 15, 15, 15, 15,
+		5, 5, 5, 5, 5, 5, 5, 5,
+		0, 0, 0, 0, 0, 0, 0, 0,
+		0, 0, 0, 0, 0, 0, 0, 0,
+	},
+	// knight
+	{
+		-50, -40, -30, -30, -30, -30, -40, -50,
+		-40, -20, 0, 0, 0, 0, -20, -40,
+		-30, 0, 10, 15, 15, 10, 0, -30,
+		-30, 5, 15, 20, 20, 15, 5, -30,
+		-30, 0, 15, 20, 20, 15, 0, -30,
+		-30, 5, 10, 15, 15, 10, 5, -30,
+		-40, -20, 0, 5, 5, 0, -20, -40,
+		-50, -40, -30, -30, -30, -30, -40, -50,
+	},
+	// bishop
+	{
+		-20, -10, -10, -10, -10, -10, -10, -20,
+		-10, 0, 0, 0, 0, 0, 0, -10,
+		-10, 0, 5, 10, 10, 5, 0, -10,
+		-10, 5, 10, 15, 15, 10, 5, -10,
+		-10, 5, 10, 15, 15, 10, 5, -10,
+		-10, 0, 5, 10, 10, 5, 0, -10,
+		-10, 0, 0, 0, 0, 0, 0, -10,
+		-20, -10, -10, -10, -10, -10, -10, -20,
+	},
+	// rook
+	{
+		0, 0, 0, 0, 0, 0, 0, 0,
+		10, 10, 10, 10, 10, 10, 10, 10,
+		0, 0, 0, 0, 0, 0, 0, 0,
+		0, 0, 0, 0, 0, 0, 0, 0,
+		0, 0, 0, 0, 0, 0, 0, 0,
+		0, 0, 0, 0, 0, 0, 0, 0,
+		0, 0, 0, 0, 0, 0, 0, 0,
+		0, 0, 0, 0, 0, 0, 0, 0,
+	},
+	// queen
+	{
+		-20, -10, -10, -5, -5, -10, -10, -20,
+		-10, 0, 5, 5, 5, 5, 0, -10,
+		-10, 5, 10, 10, 10, 10, 5, -10,
+		-5, 5, 10, 15, 15, 10, 5, -5,
+		-5, 5, 10, 15, 15, 10, 5, -5,
+		-10, 5, 10, 10, 10, 10, 5, -10,
+		-10, 0, 5, 5, 5, 5, 0, -10,
+		-20, -10, -10, -5, -5, -10, -10, -20,
+	},
+	// king
+	{
+		-50, -40, -30, -20, -20, -30, -40, -50,
+		-30, -20, -10, 0, 0, -10, -20, -30,
+		-30, -10, 20, 30, 30, 20, -10, -30,
+		-30, -10, 30, 40, 40, 30, -10, -30,
+		-30, -10, 30, 40, 40, 30, -10, -30,
+		-30, -10, 20, 30, 30, 20, -10, -30,
+		-30, -30, 0, 0, 0, 0, -30, -30,
+		-50, -30, -30, -30, -30, -30, -30, -50,
+	},
+}
@@ -0,0 +1,127 @@
+package engine
+
+import (
+	"chessBot/board"
+	"chessBot/fen"
+	"strings"
+	"testing"
+)
+
+var evalPositions = []string{
+	fen.STARTPOSFEN,
+	"r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1",
+	"8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1",
+	"rnbq1k1r/pp1Pbppp/2p5/8/2B5/8/PPP1NnPP/RNBQK2R w KQ - 1 8",
+	"4k3/8/8/3q4/8/8/3R4/4K3 b - - 0 1",
+}
+
+func TestEvaluateStartPosition(t *testing.T) {
+	b, _ := fen.FenToBoard(fen.STARTPOSFEN)
+	if score := Evaluate(b); score != 0 {
+		t.Error("expected the start position to be equal, but got", score)
+	}
+
+	b.Side = board.BLACK
+	if score := Evaluate(b); score != 0 {
+		t.Error("expected the start position to be equal for black, but got", score)
+	}
+}
+
+func TestEvaluateIsSymmetric(t *testing.T) {
+	for _, fenString := range evalPositions {
+		b, _ := fen.FenToBoard(fenString)
+		mirrored := mirror(b)
+
+		if Evaluate(b) != Evaluate(mirrored) {
+			t.Errorf("expected %s to score %d mirrored, but got %d", fenString, Evaluate(b), Evaluate(mirrored))
+		}
+	}
+}
+
+func TestEvaluateSideToMove(t *testing.T) {
+	b, _ := fen.FenToBoard("4k3/8/8/8/8/8/3Q4/4K3 w - - 0 1")
+	white := Evaluate(b)
+	b.Side = board.BLACK
+	black := Evaluate(b)
+
+	if white <= 0 || black != -white {
+		t.Errorf("expected white to be better by the same amount black is worse, but got %d and %d", white, black)
+	}
+}
+
+func TestEvaluationTerms(t *testing.T) {
+	for _, testCase := range []struct {
+		desc string
+		fen  string
+		term Term
+	}{
+		{
+			desc: "bishop pair",
+			fen:  "4k3/8/8/8/8/8/8/2B1KB2 w - - 0 1",
+			term: BISHOP_PAIR,
+		},
+		{
+			desc: "rook on open file",
+			fen:  "3k4/pppp1ppp/8/8/8/8/PPPP1PPP/4RK2 w - - 0 1",
+			term: ROOK_OPEN_FILE,
+		},
+		{
+			desc: "passed pawn",
+			fen:  "4k3/8/8/8/8/P7/8/4K3 w - - 0 1",
+			term: PASSED_PAWNS,
+		},
+	} {
+		b, _ := fen.FenToBoard(testCase.fen)
+		ev := ExplainEvaluation(b)
+
+		if ev.Terms[testCase.term].Middlegame <= 0 || ev.Terms[testCase.term].Endgame <= 0 {
+			t.Errorf("%s: expected a bonus for white, but got %+v", testCase.desc, ev.Terms[testCase.term])
+		}
+	}
+
+	// both pawns block each other
+	b, _ := fen.FenToBoard("4k3/8/8/4p3/4P3/8/8/4K3 w - - 0 1")
+	if ev := ExplainEvaluation(b); ev.Terms[PASSED_PAWNS] != (TermScore{}) {
+		t.Error("expected no passed pawns, but got", ev.Terms[PASSED_PAWNS])
+	}
+}
+
+func TestEvaluationPhase(t *testing.T) {
+	b, _ := fen.FenToBoard(fen.STARTPOSFEN)
+	if ev := ExplainEvaluation(b); ev.Phase != maxPhase {
+		t.Error("expected the middlegame phase in the start position, but got", ev.Phase)
+	}
+
+	b, _ = fen.FenToBoard("4k3/pppp4/8/8/8/8/PPPP4/4K3 w - - 0 1")
+	ev := ExplainEvaluation(b)
+	if ev.Phase != 0 {
+		t.Error("expected the endgame phase with only pawns left, but got", ev.Phase)
+	}
+
+	endgame := 0
+	for _, term := range ev.Terms {
+		endgame += term.Endgame
+	}
+	if ev.Score != endgame {
+		t.Errorf("expected only the endgame values to count, but got %d instead of %d", ev.Score, endgame)
+	}
+	if !strings.Contains(ev.String(), "passed pawns") {
+		t.Error("expected all terms to be listed, but got", ev.String())
+	}
+}
+
+// mirror returns the board with ranks flipped and colors swapped.
+func mirror(b *board.Board) *board.Board {
+	mirrored := board.NewBoard()
+	for index, cell := range b.Cells {
+		if cell.Occupant == nil {
+			continue
+		}
+		position := board.PositionFromIndex(index)
+		position.Rank = 9 - position.Rank
+		mirrored.SetPieceAt(*position, board.NewPiece(cell.Occupant.Kind, opponentOf(cell.Occupant.Color)))
+	}
+	mirrored.Side = opponentOf(b.Side)
+
+	return mirrored
+}
@@ -0,0 +1,203 @@
+package engine
+
+import (
+	"bufio"
+	"chessBot/fen"
+	"chessBot/uci"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Run reads commands from the input and answers them, until it reads quit or the input ends.
+// Searches run in the background, so that the GUI can stop them at any time.
+func (e *Engine) Run(input io.Reader) error {
+	e.Log("Welcome to Outstanding Move! Waiting for commands...\n")
+
+	reader := bufio.NewReader(input)
+	for {
+		text, err := reader.ReadString('\n')
+		if text != "" && !strings.HasSuffix(text, "\n") {
+			text += "\n"
+		}
+		if text != "" && !e.handleLine(text) {
+			e.Stop()
+			return nil
+		}
+		if err == io.EOF {
+			e.Log("end of input")
+			e.Stop()
+			return nil
+		}
+		if err != nil {
+			e.Stop()
+			return err
+		}
+	}
+}
+
+// handleLine answers one line of input. It returns false once the engine should quit.
+func (e *Engine) handleLine(text string) bool {
+	e.Log(text)
+
+	if fields := strings.Fields(text); len(fields) > 0 && fields[0] == "perft" {
+		e.perft(fields[1:])
+		return true
+	}
+
+	stmnts, err := uci.Parse(text)
+	if err != nil {
+		e.Debug("error when parsing input: " + err.Error())
+	}
+
+	for _, stmnt := range stmnts {
+		e.Log("<- " + string(stmnt.Kind))
+		switch stmnt.Kind {
+		case uci.UciStatementKind:
+			e.SendMessage(&uci.Message{Kind: uci.IdMessageKind, Id: &uci.IdMessage{Name: Name}})
+			e.SendMessage(&uci.Message{Kind: uci.IdMessageKind, Id: &uci.IdMessage{Author: Author}})
+			for _, msg := range e.options.Messages() {
+				e.SendMessage(msg)
+			}
+			e.SendMessage(&uci.Message{Kind: uci.UciOkMessageKind})
+		case uci.DebugStatementKind:
+			e.SetDebug(stmnt.Debug.On)
+		case uci.IsReadyStatementKind:
+			e.SendMessage(&uci.Message{Kind: uci.ReadyOkMessageKind})
+		case uci.SetOptionStatementKind:
+			e.setOption(stmnt.SetOption)
+		case uci.UciNewGameStatementKind:
+			e.Stop()
+			e.tt.Clear()
+		case uci.PositionStatementKind:
+			e.Log(fmt.Sprintf("%+v", stmnt.Position))
+			err = e.InitBoard(stmnt.Position)
+			if err != nil {
+				e.Debug("error initializing board: " + err.Error())
+				continue
+			}
+			e.Debug("position " + fen.BoardToFen(e.Board))
+			e.Log("Current Board:")
+			e.Log(e.Board.String())
+		case uci.GoStatementKind:
+			if e.Board == nil {
+				e.Board, _ = fen.FenToBoard(fen.STARTPOSFEN)
+				e.history = nil
+			}
+			e.Go(LimitsFromGoStatement(stmnt.Go))
+		case uci.StopStatementKind:
+			e.Stop()
+		case uci.PonderHitStatementKind:
+			e.PonderHit()
+		case uci.QuitStatementKind:
+			return false
+		}
+	}
+
+	return true
+}
+
+func (e *Engine) setOption(stmnt *uci.SetOptionStatement) {
+	// options must not change while searching
+	e.Stop()
+
+	if err := e.options.Set(stmnt.Name, stmnt.Value); err != nil {
+		e.Debug("rejected option: " + err.Error())
+	}
+}
+
+// Go starts a search of the current board in the background and sends the best move once it is
+// done. A search that is still running is stopped first.
+func (e *Engine) Go(limits SearchLimits) {
+	e.Stop()
+
+	stop := make(chan struct{})
+	ponderhit := make(chan struct{})
+	done := make(chan struct{})
+	b := e.Board.Copy()
+	history := append([]uint64{}, e.history...)
+
+	e.searchMutex.Lock()
+	e.stop, e.ponderhit, e.done = stop, ponderhit, done
+	e.searchMutex.Unlock()
+
+	go func() {
+		defer close(done)
+		result := e.think(b, history, limits, stop, ponderhit)
+
+		// the best move must not be sent before the GUI asks for it
+		switch {
+		case limits.Infinite:
+			<-stop
+		case limits.Ponder:
+			select {
+			case <-stop:
+			case <-ponderhit:
+			}
+		}
+		e.SendMessage(&uci.Message{Kind: uci.BestMoveMessageKind, BestMove: &uci.BestMoveMessage{Move: result.BestMove.String()}})
+	}()
+}
+
+// Stop ends the running search and returns once its best move is sent.
+func (e *Engine) Stop() {
+	e.searchMutex.Lock()
+	stop, done := e.stop, e.done
+	e.stop, e.ponderhit, e.done = nil, nil, nil
+	e.searchMutex.Unlock()
+
+	if stop == nil {
+		return
+	}
+	close(stop)
+	<-done
+}
+
+// PonderHit tells the running search that the opponent played the move it was pondering on.
+func (e *Engine) PonderHit() {
+	e.searchMutex.Lock()
+	defer e.searchMutex.Unlock()
+
+	if e.ponderhit == nil {
+		return
+	}
+	close(e.ponderhit)
+	e.ponderhit = nil
+}
+
+// Wait blocks until the running search sent its best move.
+func (e *Engine) Wait() {
+	e.searchMutex.Lock()
+	done := e.done
+	e.searchMutex.Unlock()
+
+	if done != nil {
+		<-done
+	}
+}
+
+// perft is no UCI command. It counts the leaf nodes below each move of the current position,
+// which helps to verify the move generation against other engines.
+func (e *Engine) perft(args []string) {
+	if len(args) != 1 {
+		e.Send("usage: perft <depth>")
+		return
+	}
+	depth, err := strconv.Atoi(args[0])
+	if err != nil || depth < 1 {
+		e.Send("perft depth has to be a positive number")
+		return
+	}
+	if e.Board == nil {
+		e.Board, _ = fen.FenToBoard(fen.STARTPOSFEN)
+	}
+
+	total := 0
+	for _, division := range Divide(e.Board, depth) {
+		e.Send(fmt.Sprintf("%s: %d", division.Move, division.Nodes))
+		total += division.Nodes
+	}
+	e.Send("")
+	e.Send(fmt.Sprintf("Nodes searched: %d", total))
+}
@@ -0,0 +1,255 @@
+package engine
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+// gui talks to an engine running in the background, like a chess GUI would.
+type gui struct {
+	t      *testing.T
+	input  *io.PipeWriter
+	lines  chan string
+	result chan error
+}
+
+func startGui(t *testing.T) *gui {
+	inputReader, inputWriter := io.Pipe()
+	outputReader, outputWriter := io.Pipe()
+	g := &gui{
+		t:      t,
+		input:  inputWriter,
+		lines:  make(chan string, 1000),
+		result: make(chan error, 1),
+	}
+
+	e := NewEngine(Config{Output: outputWriter})
+	go func() {
+		g.result <- e.Run(inputReader)
+		outputWriter.Close()
+	}()
+	go func() {
+		scanner := bufio.NewScanner(outputReader)
+		for scanner.Scan() {
+			g.lines <- scanner.Text()
+		}
+		close(g.lines)
+	}()
+
+	return g
+}
+
+func (g *gui) send(command string) {
+	_, err := io.WriteString(g.input, command+"\n")
+	if err != nil {
+		g.t.Fatal("error sending", command, err)
+	}
+}
+
+// expect waits for a line starting with prefix, skipping info lines.
+func (g *gui) expect(prefix string, timeout time.Duration) string {
+	deadline := time.After(timeout)
+	for {
+		select {
+		case line, ok := <-g.lines:
+			if !ok {
+				g.t.Fatalf("expected %q, but the engine stopped", prefix)
+			}
+			if strings.HasPrefix(line, prefix) {
+				return line
+			}
+			if !strings.HasPrefix(line, "info") {
+				g.t.Fatalf("expected %q, but got %q", prefix, line)
+			}
+		case <-deadline:
+			g.t.Fatalf("expected %q within %s", prefix, timeout)
+		}
+	}
+}
+
+// expectNothing makes sure that nothing but info lines is sent for the given time.
+func (g *gui) expectNothing(duration time.Duration) {
+	deadline := time.After(duration)
+	for {
+		select {
+		case line := <-g.lines:
+			if !strings.HasPrefix(line, "info") {
+				g.t.Fatalf("expected nothing, but got %q", line)
+			}
+		case <-deadline:
+			return
+		}
+	}
+}
+
+func (g *gui) expectQuit() {
+	select {
+	case err := <-g.result:
+		if err != nil {
+			g.t.Error("unexpected error", err)
+		}
+	case <-time.After(2 * time.Second):
+		g.t.Fatal("expected the engine to quit")
+	}
+}
+
+func TestRunHandshake(t *testing.T) {
+	g := startGui(t)
+	g.send("uci")
+	g.expect("id name Outstanding Move", time.Second)
+	g.expect("id author the Outstanding Move authors", time.Second)
+	g.expect("option name Hash type spin default 16 min 1 max 1024", time.Second)
+	g.expect("option name Clear Hash type button", time.Second)
+	g.expect("uciok", time.Second)
+	g.send("isready")
+	g.expect("readyok", time.Second)
+	g.send("quit")
+	g.expectQuit()
+}
+
+func TestRunQuitsAtEndOfInput(t *testing.T) {
+	g := startGui(t)
+	g.send("isready")
+	g.expect("readyok", time.Second)
+	g.input.Close()
+	g.expectQuit()
+}
+
+func TestRunGoInfinite(t *testing.T) {
+	g := startGui(t)
+	g.send("position startpos moves e2e4")
+	g.send("go infinite")
+	g.expectNothing(200 * time.Millisecond)
+
+	g.send("isready")
+	g.expect("readyok", time.Second)
+
+	g.send("stop")
+	g.expect("bestmove ", time.Second)
+	g.send("quit")
+	g.expectQuit()
+}
+
+func TestRunGoMoveTime(t *testing.T) {
+	g := startGui(t)
+	g.send("position startpos moves")
+	g.send("go movetime 100")
+	g.expect("bestmove ", 2*time.Second)
+	g.send("quit")
+	g.expectQuit()
+}
+
+func TestRunPonder(t *testing.T) {
+	g := startGui(t)
+	g.send("position startpos moves e2e4 e7e5")
+	g.send("go ponder wtime 200 btime 200")
+	// pondering does not use the clocks
+	g.expectNothing(500 * time.Millisecond)
+
+	g.send("ponderhit")
+	g.expect("bestmove ", 2*time.Second)
+	g.send("quit")
+	g.expectQuit()
+}
+
+func TestRunQuitStopsSearch(t *testing.T) {
+	g := startGui(t)
+	g.send("position startpos moves")
+	g.send("go infinite")
+	g.send("quit")
+	g.expectQuit()
+}
+
+func TestRunMated(t *testing.T) {
+	g := startGui(t)
+	g.send("position startpos moves f2f3 e7e5 g2g4 d8h4")
+	g.send("go depth 3")
+	if line := g.expect("bestmove", time.Second); line != "bestmove 0000" {
+		t.Error("expected the null move, but got", line)
+	}
+	g.send("quit")
+	g.expectQuit()
+}
+
+func TestRunPositionFen(t *testing.T) {
+	g := startGui(t)
+	g.send("position fen 6k1/5ppp/8/8/8/8/8/R5K1 w - - 0 1")
+	g.send("go depth 2")
+	if line := g.expect("bestmove", time.Second); line != "bestmove a1a8" {
+		t.Error("expected the mate, but got", line)
+	}
+
+	g.send("position fen 6k1/5ppp/8/8/8/8/8/R5K1 w - - 0 1 moves a1a2 g8h8")
+	g.send("go depth 2")
+	if line := g.expect("bestmove", time.Second); line != "bestmove a2a8" {
+		t.Error("expected the mate after the moves, but got", line)
+	}
+	g.send("quit")
+	g.expectQuit()
+}
+
+func TestRunInvalidPosition(t *testing.T) {
+	g := startGui(t)
+	g.send("position startpos moves e2e4")
+	// the position is kept up to the last legal move
+	g.send("position fen 6k1/5ppp/8/8/8/8/8/R5K1 w - - 0 1 moves a1a2 g8h8 a2b3 h8g8")
+	g.send("go depth 2")
+	if line := g.expect("bestmove", time.Second); line != "bestmove a2a8" {
+		t.Error("expected the mate before the illegal move, but got", line)
+	}
+
+	// without a position there is no move, the previous game must not be searched
+	g.send("position fen 6k1/5ppp/8/8/8/8/8/R5K1 x - - 0 1")
+	g.send("go depth 2")
+	if line := g.expect("bestmove", time.Second); line != "bestmove 0000" {
+		t.Error("expected no move after an invalid fen, but got", line)
+	}
+	g.send("quit")
+	g.expectQuit()
+}
+
+func TestRunSkipsInvalidLines(t *testing.T) {
+	g := startGui(t)
+	g.send("setoption")
+	g.send("position startpos moves e2e5")
+	g.send("joho isready")
+	g.expect("readyok", time.Second)
+	g.send("quit")
+	g.expectQuit()
+}
+
+func TestDebugMode(t *testing.T) {
+	var output strings.Builder
+	e := NewEngine(Config{Output: &output})
+
+	e.handleLine("setoption name Hash value 0\n")
+	e.handleLine("position startpos\n")
+	if strings.Contains(output.String(), "info string") {
+		t.Errorf("expected no diagnostics without debug mode, but got %q", output.String())
+	}
+
+	e.handleLine("debug on\n")
+	e.handleLine("setoption name Hash value 0\n")
+	e.handleLine("position startpos moves e2e4\n")
+	e.Search(SearchLimits{Depth: 1})
+	for _, expected := range []string{
+		"info string rejected option: Hash expects a number from 1 to 1024, but got 0\n",
+		"info string position rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq",
+		"info string search ended after depth 1: depth limit reached\n",
+	} {
+		if !strings.Contains(output.String(), expected) {
+			t.Errorf("expected %q in debug mode, but got %q", expected, output.String())
+		}
+	}
+
+	output.Reset()
+	e.handleLine("debug off\n")
+	e.handleLine("go movetime 10\n")
+	e.Wait()
+	if strings.Contains(output.String(), "info string") {
+		t.Errorf("expected no diagnostics after debug off, but got %q", output.String())
+	}
+}
@@ -0,0 +1,246 @@
+package engine
+
+import (
+	"chessBot/board"
+)
+
+var promotionKinds = []board.ChessPieceKind{board.QUEEN, board.ROOK, board.BISHOP, board.KNIGHT}
+
+// squares translates the index of a square to its position
+var squares [64]board.Position
+
+// castlingPaths holds the squares between king and rook, which have to be empty, and the squares
+// the king travels including its own, which must not be attacked, indexed by Castling.
+var castlingPaths [4]struct {
+	empty board.Bitboard
+	safe  board.Bitboard
+}
+
+func init() {
+	for index := range squares {
+		squares[index] = *board.PositionFromIndex(index)
+	}
+
+	for _, castling := range board.CastlingMoves {
+		path := &castlingPaths[castling.Castling]
+		path.empty = squaresBetween(castling.KingFrom.Index(), castling.RookFrom.Index())
+		path.safe = squaresBetween(castling.KingFrom.Index(), castling.KingTo.Index()) |
+			board.SquareBitboard(castling.KingFrom.Index()) | board.SquareBitboard(castling.KingTo.Index())
+	}
+}
+
+// squaresBetween returns the squares between two squares on the same rank, without both of them.
+func squaresBetween(from int, to int) board.Bitboard {
+	if from > to {
+		from, to = to, from
+	}
+	var between board.Bitboard
+	for index := from + 1; index < to; index++ {
+		between |= board.SquareBitboard(index)
+	}
+
+	return between
+}
+
+func generateMoves(b *board.Board, filterMoves bool) []board.Move {
+	return appendMoves(nil, board.NewBitboards(b), filterMoves)
+}
+
+// appendMoves appends the moves of the side to move to moves. Unless legal is set, this includes
+// moves leaving the own king in check. The moves are ordered by the square they start from.
+//
+// Legal moves are found without making them: in check only moves capturing or blocking the single
+// checker are possible, pinned pieces stay on the line to their king and the king only goes to
+// squares that are not attacked once it has left its own.
+func appendMoves(moves []board.Move, bb *board.Bitboards, legal bool) []board.Move {
+	side := bb.Side
+	targets := ^bb.Colors[side]
+	kingTargets := targets
+
+	king := -1
+	var pinned board.Bitboard
+	if legal && bb.Pieces[side][board.KING] != 0 {
+		king = bb.King(side)
+		pinned = bb.Pinned(side)
+		checkers := bb.Checkers()
+		switch checkers.Count() {
+		case 0:
+		case 1:
+			targets &= checkers | board.Between(king, checkers.First())
+		default:
+			targets = 0
+		}
+	}
+
+	pieces := bb.Colors[side]
+	for pieces != 0 {
+		from := pieces.Pop()
+		kind, _, _ := bb.PieceAt(from)
+
+		allowed := targets
+		if pinned.Has(from) {
+			allowed &= board.Line(king, from)
+		}
+
+		var attacks board.Bitboard
+		switch kind {
+		case board.PAWN:
+			moves = appendPawnMoves(moves, bb, from, allowed, legal)
+			continue
+		case board.KNIGHT:
+			attacks = board.KnightAttacks(from) & allowed
+		case board.BISHOP:
+			attacks = board.BishopAttacks(from, bb.Occupied) & allowed
+		case board.ROOK:
+			attacks = board.RookAttacks(from, bb.Occupied) & allowed
+		case board.QUEEN:
+			attacks = board.QueenAttacks(from, bb.Occupied) & allowed
+		case board.KING:
+			attacks = board.KingAttacks(from) & kingTargets
+		}
+
+		for attacks != 0 {
+			to := attacks.Pop()
+			if from == king && bb.Attackers(to, side^1, bb.Occupied&^board.SquareBitboard(from)) != 0 {
+				continue
+			}
+			move := board.Move{From: squares[from], To: squares[to]}
+			if captured, _, ok := bb.PieceAt(to); ok {
+				move.IsCapture = true
+				move.Captured = captured
+			}
+			moves = append(moves, move)
+		}
+	}
+
+	return appendCastlingMoves(moves, bb)
+}
+
+// appendPawnMoves adds the moves of the pawn to the allowed squares.
+func appendPawnMoves(moves []board.Move, bb *board.Bitboards, from int, allowed board.Bitboard, legal bool) []board.Move {
+	side := bb.Side
+	forward, startRank := 8, 1
+	if side == board.BLACK {
+		forward, startRank = -8, 6
+	}
+
+	// moves
+	to := from + forward
+	if to >= 0 && to < 64 && !bb.Occupied.Has(to) {
+		if allowed.Has(to) {
+			moves = appendPawnMove(moves, board.Move{From: squares[from], To: squares[to]})
+		}
+		if from>>3 == startRank && !bb.Occupied.Has(to+forward) && allowed.Has(to+forward) {
+			moves = append(moves, board.Move{
+				From:             squares[from],
+				To:               squares[to+forward],
+				IsDoublePawnPush: true,
+			})
+		}
+	}
+
+	// strikes
+	attacks := board.PawnAttacks(side, from)
+	captures := attacks & bb.Colors[side^1] & allowed
+	for captures != 0 {
+		to := captures.Pop()
+		captured, _, _ := bb.PieceAt(to)
+		moves = appendPawnMove(moves, board.Move{
+			From:      squares[from],
+			To:        squares[to],
+			IsCapture: true,
+			Captured:  captured,
+		})
+	}
+	if bb.EnPassant >= 0 && attacks.Has(bb.EnPassant) && (!legal || !enPassantLeavesKingInCheck(bb, from, bb.EnPassant)) {
+		moves = append(moves, board.Move{
+			From:        squares[from],
+			To:          squares[bb.EnPassant],
+			IsCapture:   true,
+			Captured:    board.PAWN,
+			IsEnPassant: true,
+		})
+	}
+
+	return moves
+}
+
+// enPassantLeavesKingInCheck looks at the board after the capture, as it removes two pieces from
+// the same rank and can uncover an attack none of them was pinned for.
+func enPassantLeavesKingInCheck(bb *board.Bitboards, from int, to int) bool {
+	side := bb.Side
+	if bb.Pieces[side][board.KING] == 0 {
+		return false
+	}
+
+	captured := board.SquareBitboard(to&7 | from&^7)
+	occupied := bb.Occupied&^board.SquareBitboard(from)&^captured | board.SquareBitboard(to)
+
+	return bb.Attackers(bb.King(side), side^1, occupied)&^captured != 0
+}
+
+// appendPawnMove adds the move, or all four promotions if the pawn reaches the last rank.
+func appendPawnMove(moves []board.Move, move board.Move) []board.Move {
+	if move.To.Rank != 1 && move.To.Rank != 8 {
+		return append(moves, move)
+	}
+
+	for _, kind := range promotionKinds {
+		move.Promotion = kind
+		moves = append(moves, move)
+	}
+
+	return moves
+}
+
+func appendCastlingMoves(moves []board.Move, bb *board.Bitboards) []board.Move {
+	side := bb.Side
+	for _, castling := range board.CastlingMoves {
+		if castling.Side != side || !bb.CanCastle(castling.Castling) {
+			continue
+		}
+		if !bb.Pieces[side][board.KING].Has(castling.KingFrom.Index()) || !bb.Pieces[side][board.ROOK].Has(castling.RookFrom.Index()) {
+			continue
+		}
+		path := castlingPaths[castling.Castling]
+		if bb.Occupied&path.empty != 0 || isAnySquareAttacked(bb, path.safe, side^1) {
+			continue
+		}
+
+		moves = append(moves, board.Move{
+			From:       castling.KingFrom,
+			To:         castling.KingTo,
+			IsCastling: true,
+		})
+	}
+
+	return moves
+}
+
+func isAnySquareAttacked(bb *board.Bitboards, targets board.Bitboard, attacker board.Color) bool {
+	for targets != 0 {
+		if bb.IsSquareAttacked(targets.Pop(), attacker) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func opponentOf(side board.Color) board.Color {
+	if side == board.WHITE {
+		return board.BLACK
+	}
+
+	return board.WHITE
+}
+
+// LegalMoves returns the moves the side to move may play on b.
+func LegalMoves(b *board.Board) []board.Move {
+	return generateMoves(b, true)
+}
+
+// InCheck tells whether the king of the side to move is attacked.
+func InCheck(b *board.Board) bool {
+	return board.NewBitboards(b).InCheck()
+}
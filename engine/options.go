@@ -0,0 +1,151 @@
+package engine
+
+import (
+	"chessBot/uci"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Option is a setting of the engine that the GUI can change with setoption.
+type Option struct {
+	// Name may contain spaces, it is matched in any case.
+	Name    string
+	Type    uci.OptionType
+	Default string
+	// Min and Max bound the value of spin options.
+	Min int
+	Max int
+	// Vars are the values of combo options.
+	Vars []string
+	// OnChange is called with the new value once it is validated. Buttons are called with an empty
+	// value each time they are pushed.
+	OnChange func(value string)
+
+	value string
+}
+
+// Value returns the current value, which is the default until the option is set.
+func (o *Option) Value() string {
+	return o.value
+}
+
+// Message returns the option as it is advertised to the GUI.
+func (o *Option) Message() *uci.Message {
+	return &uci.Message{Kind: uci.OptionMessageKind, Option: &uci.OptionMessage{
+		Name:    o.Name,
+		Type:    o.Type,
+		Default: o.Default,
+		Min:     o.Min,
+		Max:     o.Max,
+		Vars:    o.Vars,
+	}}
+}
+
+// validate returns the value as the option stores it, or an error if the option cannot take it.
+func (o *Option) validate(value string) (string, error) {
+	switch o.Type {
+	case uci.CHECK:
+		switch strings.ToLower(value) {
+		case "true":
+			return "true", nil
+		case "false":
+			return "false", nil
+		}
+		return "", fmt.Errorf("%s expects true or false, but got %q", o.Name, value)
+	case uci.SPIN:
+		number, err := strconv.Atoi(value)
+		if err != nil {
+			return "", fmt.Errorf("%s expects a number, but got %q", o.Name, value)
+		}
+		if number < o.Min || number > o.Max {
+			return "", fmt.Errorf("%s expects a number from %d to %d, but got %d", o.Name, o.Min, o.Max, number)
+		}
+		return strconv.Itoa(number), nil
+	case uci.COMBO:
+		for _, v := range o.Vars {
+			if strings.EqualFold(v, value) {
+				return v, nil
+			}
+		}
+		return "", fmt.Errorf("%s expects one of %s, but got %q", o.Name, strings.Join(o.Vars, ", "), value)
+	case uci.BUTTON:
+		return "", nil
+	case uci.STRING:
+		if value == "<empty>" {
+			return "", nil
+		}
+		return value, nil
+	}
+
+	return "", fmt.Errorf("%s has the unknown type %s", o.Name, o.Type)
+}
+
+// Options holds the options of an engine in the order they were added.
+type Options struct {
+	options []*Option
+	byName  map[string]*Option
+}
+
+func NewOptions() *Options {
+	return &Options{byName: make(map[string]*Option)}
+}
+
+// Add registers the option with its default value. The default has to be valid, and no other option
+// may have the same name.
+func (opts *Options) Add(option *Option) error {
+	key := strings.ToLower(option.Name)
+	if key == "" {
+		return errors.New("option without name")
+	}
+	if _, ok := opts.byName[key]; ok {
+		return fmt.Errorf("option %s already exists", option.Name)
+	}
+	value, err := option.validate(option.Default)
+	if err != nil {
+		return fmt.Errorf("invalid default: %w", err)
+	}
+
+	option.value = value
+	opts.options = append(opts.options, option)
+	opts.byName[key] = option
+
+	return nil
+}
+
+// Get finds the option by its name in any case.
+func (opts *Options) Get(name string) (*Option, bool) {
+	option, ok := opts.byName[strings.ToLower(name)]
+	return option, ok
+}
+
+// Set validates the value and calls the OnChange of the option. Invalid values leave the option as
+// it was.
+func (opts *Options) Set(name string, value string) error {
+	option, ok := opts.Get(name)
+	if !ok {
+		return fmt.Errorf("unknown option %s", name)
+	}
+	value, err := option.validate(value)
+	if err != nil {
+		return err
+	}
+
+	option.value = value
+	if option.OnChange != nil {
+		option.OnChange(value)
+	}
+
+	return nil
+}
+
+// Messages returns the options as they are advertised to the GUI.
+func (opts *Options) Messages() []*uci.Message {
+	messages := make([]*uci.Message, 0, len(opts.options))
+	for _, option := range opts.options {
+		messages = append(messages, option.Message())
+	}
+
+	return messages
+}
@@ -0,0 +1,87 @@
+package engine
+
+import (
+	"chessBot/uci"
+	"testing"
+)
+
+func TestOptionsValidation(t *testing.T) {
+	var changes []string
+	record := func(value string) {
+		changes = append(changes, value)
+	}
+
+	opts := NewOptions()
+	for _, option := range []*Option{
+		{Name: "Ponder", Type: uci.CHECK, Default: "false", OnChange: record},
+		{Name: "Threads", Type: uci.SPIN, Default: "1", Min: 1, Max: 8, OnChange: record},
+		{Name: "Style", Type: uci.COMBO, Default: "Normal", Vars: []string{"Solid", "Normal", "Risky"}, OnChange: record},
+		{Name: "Clear Hash", Type: uci.BUTTON, OnChange: record},
+		{Name: "Book File", Type: uci.STRING, Default: "<empty>", OnChange: record},
+	} {
+		if err := opts.Add(option); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		value   string
+		valid   bool
+		changed string
+	}{
+		{"ponder", "TRUE", true, "true"},
+		{"Ponder", "yes", false, ""},
+		{"threads", "4", true, "4"},
+		{"Threads", "9", false, ""},
+		{"Threads", "many", false, ""},
+		{"STYLE", "risky", true, "Risky"},
+		{"Style", "Wild", false, ""},
+		{"clear hash", "", true, ""},
+		{"Book File", "C:\\books\\main book.bin", true, "C:\\books\\main book.bin"},
+		{"Book File", "<empty>", true, ""},
+		{"Contempt", "10", false, ""},
+	}
+	for _, test := range tests {
+		changes = nil
+		err := opts.Set(test.name, test.value)
+		if test.valid && err != nil {
+			t.Errorf("expected %s to take %q, but got %v", test.name, test.value, err)
+			continue
+		}
+		if !test.valid {
+			if err == nil {
+				t.Errorf("expected %s to reject %q", test.name, test.value)
+			}
+			if len(changes) != 0 {
+				t.Errorf("expected no change after rejecting %q", test.value)
+			}
+			continue
+		}
+		if len(changes) != 1 || changes[0] != test.changed {
+			t.Errorf("expected %s to change to %q, but got %q", test.name, test.changed, changes)
+		}
+	}
+
+	if option, _ := opts.Get("threads"); option.Value() != "4" {
+		t.Errorf("expected the rejected values to keep 4 threads, but got %s", option.Value())
+	}
+}
+
+func TestOptionsAdd(t *testing.T) {
+	opts := NewOptions()
+	if err := opts.Add(&Option{Name: "Hash", Type: uci.SPIN, Default: "16", Min: 1, Max: 1024}); err != nil {
+		t.Fatal(err)
+	}
+	if err := opts.Add(&Option{Name: "hash", Type: uci.CHECK, Default: "true"}); err == nil {
+		t.Error("expected names to be unique in any case")
+	}
+	if err := opts.Add(&Option{Name: "Threads", Type: uci.SPIN, Default: "0", Min: 1, Max: 8}); err == nil {
+		t.Error("expected the default to be within the bounds")
+	}
+
+	messages := opts.Messages()
+	if len(messages) != 1 || messages[0].String() != "option name Hash type spin default 16 min 1 max 1024" {
+		t.Errorf("expected only the Hash option, but got %v", messages)
+	}
+}
@@ -0,0 +1,69 @@
+package engine
+
+import (
+	"chessBot/board"
+	"sort"
+)
+
+// Division is the number of leaf nodes found below one of the root moves.
+type Division struct {
+	Move  board.Move
+	Nodes int
+}
+
+// Perft counts the leaf nodes of the legal move tree of the given depth. The position itself is the
+// only node at depth 0, there are none below it.
+func Perft(b *board.Board, depth int) int {
+	if depth < 0 {
+		return 0
+	}
+
+	return perft(*board.NewBitboards(b), depth, make([][]board.Move, depth+1))
+}
+
+// Divide works like Perft, but reports the nodes for each root move separately, sorted by move.
+func Divide(b *board.Board, depth int) []Division {
+	var divisions []Division
+	if depth < 1 {
+		return divisions
+	}
+	bb := board.NewBitboards(b)
+	buffers := make([][]board.Move, depth)
+	for _, move := range appendMoves(nil, bb, true) {
+		after := *bb
+		after.MakeMove(move)
+		divisions = append(divisions, Division{
+			Move:  move,
+			Nodes: perft(after, depth-1, buffers),
+		})
+	}
+
+	sort.Slice(divisions, func(a int, b int) bool {
+		return divisions[a].Move.String() < divisions[b].Move.String()
+	})
+
+	return divisions
+}
+
+// perft generates the moves of each depth into the buffer of the depth, so that they are only
+// allocated once.
+func perft(bb board.Bitboards, depth int, buffers [][]board.Move) int {
+	if depth == 0 {
+		return 1
+	}
+
+	moves := appendMoves(buffers[depth][:0], &bb, true)
+	buffers[depth] = moves
+	if depth == 1 {
+		return len(moves)
+	}
+
+	nodes := 0
+	for _, move := range moves {
+		after := bb
+		after.MakeMove(move)
+		nodes += perft(after, depth-1, buffers)
+	}
+
+	return nodes
+}
@@ -0,0 +1,117 @@
+package engine
+
+import (
+	"chessBot/fen"
+	"testing"
+)
+
+// positions and node counts from https://www.chessprogramming.org/Perft_Results
+var perftPositions = []struct {
+	desc  string
+	fen   string
+	nodes []int
+}{
+	{
+		desc:  "start position",
+		fen:   fen.STARTPOSFEN,
+		nodes: []int{20, 400, 8902, 197281},
+	},
+	{
+		desc:  "kiwipete",
+		fen:   "r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1",
+		nodes: []int{48, 2039, 97862, 4085603},
+	},
+	{
+		desc:  "position 3",
+		fen:   "8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1",
+		nodes: []int{14, 191, 2812, 43238, 674624},
+	},
+	{
+		desc:  "position 4",
+		fen:   "r3k2r/Pppp1ppp/1b3nbN/nP6/BBP1P3/q4N2/Pp1P2PP/R2Q1RK1 w kq - 0 1",
+		nodes: []int{6, 264, 9467, 422333},
+	},
+	{
+		desc:  "position 4 mirrored",
+		fen:   "r2q1rk1/pP1p2pp/Q4n2/bbp1p3/Np6/1B3NBn/pPPP1PPP/R3K2R b KQ - 0 1",
+		nodes: []int{6, 264, 9467},
+	},
+	{
+		desc:  "position 5",
+		fen:   "rnbq1k1r/pp1Pbppp/2p5/8/2B5/8/PPP1NnPP/RNBQK2R w KQ - 1 8",
+		nodes: []int{44, 1486, 62379},
+	},
+	{
+		desc:  "position 6",
+		fen:   "r4rk1/1pp1qppp/p1np1n2/2b1p1B1/2B1P1b1/P1NP1N2/1PP1QPPP/R4RK1 w - - 0 10",
+		nodes: []int{46, 2079, 89890},
+	},
+}
+
+func TestPerft(t *testing.T) {
+	for _, position := range perftPositions {
+		for i, expected := range position.nodes {
+			depth := i + 1
+			if testing.Short() && depth > 2 {
+				break
+			}
+			b, err := fen.FenToBoard(position.fen)
+			if err != nil {
+				t.Fatal(err)
+			}
+			before := b.String()
+
+			actual := Perft(b, depth)
+			if actual != expected {
+				t.Errorf("%s: expected %d nodes at depth %d, but got %d", position.desc, expected, depth, actual)
+			}
+			if b.String() != before {
+				t.Errorf("%s: expected board to be unchanged after perft", position.desc)
+			}
+		}
+	}
+}
+
+func TestPerftWithoutDepth(t *testing.T) {
+	b, _ := fen.FenToBoard(fen.STARTPOSFEN)
+	for depth, expected := range map[int]int{0: 1, -1: 0, -5: 0} {
+		if actual := Perft(b, depth); actual != expected {
+			t.Errorf("expected %d nodes at depth %d, but got %d", expected, depth, actual)
+		}
+	}
+}
+
+func TestDivide(t *testing.T) {
+	b, _ := fen.FenToBoard(fen.STARTPOSFEN)
+	divisions := Divide(b, 3)
+
+	if len(divisions) != 20 {
+		t.Fatal("expected 20 root moves, but got", len(divisions))
+	}
+
+	total := 0
+	for _, division := range divisions {
+		total += division.Nodes
+	}
+	if total != 8902 {
+		t.Error("expected 8902 nodes in total, but got", total)
+	}
+
+	if divisions[0].Move.String() != "a2a3" || divisions[0].Nodes != 380 {
+		t.Errorf("expected a2a3 with 380 nodes first, but got %s with %d", divisions[0].Move, divisions[0].Nodes)
+	}
+}
+
+func BenchmarkPerft(b *testing.B) {
+	for _, position := range perftPositions[:2] {
+		board, err := fen.FenToBoard(position.fen)
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.Run(position.desc, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				Perft(board, 3)
+			}
+		})
+	}
+}
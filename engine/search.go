@@ -0,0 +1,493 @@
+package engine
+
+import (
+	"chessBot/board"
+	"chessBot/uci"
+	"fmt"
+	"sort"
+	"time"
+)
+
+const (
+	// MateScore is the score of a mate at the root. Mates further away score lower, so that
+	// the search prefers the shortest mate.
+	MateScore = 100000
+	infinity  = MateScore + 1
+	maxDepth  = 64
+	// defaultDepth is searched when a go command does not limit the search at all.
+	defaultDepth = 5
+)
+
+// SearchLimits stop the search once any of them is reached. Zero values do not limit the search.
+type SearchLimits struct {
+	Depth       int
+	Nodes       int
+	MoveTime    time.Duration
+	SearchMoves []board.Move
+	// Infinite searches until stopped, the best move is only sent after stop.
+	Infinite bool
+	// Ponder searches on the opponent's time until ponderhit or stop. The clocks only count from ponderhit on.
+	Ponder bool
+
+	// the clocks of both sides, the engine decides on its own how much of its time it uses
+	WhiteTime      time.Duration
+	BlackTime      time.Duration
+	WhiteIncrement time.Duration
+	BlackIncrement time.Duration
+	MovesToGo      int
+}
+
+// LimitsFromGoStatement translates the limits of a go command.
+func LimitsFromGoStatement(stmnt *uci.GoStatement) SearchLimits {
+	limits := SearchLimits{
+		Depth:          stmnt.Depth,
+		Nodes:          stmnt.Nodes,
+		MoveTime:       time.Duration(stmnt.MoveTime) * time.Millisecond,
+		WhiteTime:      time.Duration(stmnt.Wtime) * time.Millisecond,
+		BlackTime:      time.Duration(stmnt.Btime) * time.Millisecond,
+		WhiteIncrement: time.Duration(stmnt.Winc) * time.Millisecond,
+		BlackIncrement: time.Duration(stmnt.Binc) * time.Millisecond,
+		MovesToGo:      stmnt.MovesToGo,
+	}
+	for _, kind := range stmnt.Kinds {
+		switch kind {
+		case uci.Go_inifiniteKind:
+			limits.Infinite = true
+		case uci.Go_ponderKind:
+			limits.Ponder = true
+		}
+	}
+	for _, moveString := range stmnt.SearchMoves {
+		move, err := board.ParseMove(moveString)
+		if err != nil {
+			continue
+		}
+		limits.SearchMoves = append(limits.SearchMoves, move)
+	}
+	if limits.Depth == 0 && limits.Nodes == 0 && limits.MoveTime == 0 && limits.WhiteTime == 0 && limits.BlackTime == 0 && !limits.Infinite && !limits.Ponder {
+		limits.Depth = defaultDepth
+	}
+
+	return limits
+}
+
+type SearchResult struct {
+	// BestMove is the null move if the side to move has no legal move.
+	BestMove board.Move
+	// Score is in centipawns from the point of view of the side to move.
+	Score int
+	Depth int
+	Nodes int
+	PV    []board.Move
+}
+
+type search struct {
+	engine *Engine
+	board  *board.Bitboards
+	// history holds the hashes of the positions of the game and the line being searched, up to
+	// the current one, root is the index of the position the search started in
+	history   []uint64
+	root      int
+	tt        *TranspositionTable
+	limits    SearchLimits
+	clock     Clock
+	start     time.Time
+	softTime  time.Duration
+	hardTime  time.Duration
+	nodes     int
+	stopped   bool
+	pondering bool
+	stop      <-chan struct{}
+	ponderhit <-chan struct{}
+
+	// stopReason tells why the search was aborted
+	stopReason string
+}
+
+// Search looks for the best move in the current position by iterative deepening. After each
+// completed depth an info line is sent. Search blocks until a limit is reached, use Go to search in
+// the background.
+func (e *Engine) Search(limits SearchLimits) SearchResult {
+	return e.think(e.Board, e.history, limits, nil, nil)
+}
+
+// think searches the board, until one of the limits is reached or stop is closed. If the limits
+// ask for pondering, the clocks are ignored until ponderhit is closed. The history holds the
+// hashes of the positions played before the board.
+func (e *Engine) think(b *board.Board, history []uint64, limits SearchLimits, stop <-chan struct{}, ponderhit <-chan struct{}) SearchResult {
+	s := &search{
+		engine:    e,
+		board:     board.NewBitboards(b),
+		history:   append(append([]uint64{}, history...), b.Hash()),
+		root:      len(history),
+		tt:        e.tt,
+		limits:    limits,
+		clock:     e.config.Clock,
+		pondering: limits.Ponder,
+		stop:      stop,
+		ponderhit: ponderhit,
+	}
+	s.start = s.clock.Now()
+	s.tt.NewSearch()
+	s.softTime, s.hardTime = allocateTime(limits, s.board.Side)
+	if s.hardTime > 0 {
+		e.Debug(fmt.Sprintf("thinking for %s, at most %s", s.softTime, s.hardTime))
+	}
+
+	rootMoves := s.rootMoves()
+	if len(rootMoves) == 0 {
+		score := 0
+		if s.board.InCheck() {
+			score = -MateScore
+		}
+		e.Debug("no move to search")
+		return SearchResult{BestMove: board.NullMove, Score: score}
+	}
+
+	result := SearchResult{BestMove: rootMoves[0]}
+	depthLimit := maxDepth
+	if limits.Depth > 0 && limits.Depth < maxDepth {
+		depthLimit = limits.Depth
+	}
+
+	reason := "depth limit reached"
+	for depth := 1; depth <= depthLimit; depth++ {
+		var pv []board.Move
+		score := s.searchRoot(rootMoves, depth, result.PV, &pv)
+		if s.stopped {
+			break
+		}
+
+		result = SearchResult{
+			BestMove: pv[0],
+			Score:    score,
+			Depth:    depth,
+			Nodes:    s.nodes,
+			PV:       pv,
+		}
+		e.SendMessage(s.info(result))
+
+		if score >= MateScore-depth || score <= -MateScore+depth {
+			// a mate was found within the full width search, deeper searches will not find a shorter one
+			reason = "found a mate"
+			break
+		}
+		// the next iteration takes at least as long as all previous ones, so we do not start it
+		// if it would most likely run past the soft limit
+		s.poll()
+		if s.stopped {
+			break
+		}
+		if !s.pondering && s.softTime > 0 && s.elapsed() >= s.softTime/2 {
+			reason = "next depth would exceed the soft time limit"
+			break
+		}
+	}
+	if s.stopped {
+		reason = s.stopReason
+	}
+	e.Debug(fmt.Sprintf("search ended after depth %d: %s", result.Depth, reason))
+	result.Nodes = s.nodes
+
+	return result
+}
+
+func (s *search) rootMoves() []board.Move {
+	moves := appendMoves(nil, s.board, true)
+	if len(s.limits.SearchMoves) == 0 {
+		return moves
+	}
+
+	var restricted []board.Move
+	for _, move := range moves {
+		for _, searchMove := range s.limits.SearchMoves {
+			if move.SameAs(searchMove) {
+				restricted = append(restricted, move)
+				break
+			}
+		}
+	}
+
+	return restricted
+}
+
+func (s *search) searchRoot(moves []board.Move, depth int, previousPV []board.Move, pv *[]board.Move) int {
+	pvMove := board.NullMove
+	if len(previousPV) > 0 {
+		pvMove = previousPV[0]
+	}
+	s.orderMoves(moves, packMove(pvMove))
+
+	alpha := -infinity
+	for _, move := range moves {
+		var line []board.Move
+		undo := s.makeMove(move)
+		score := -s.negamax(depth-1, 1, -infinity, -alpha, &line)
+		s.unmakeMove(undo)
+		if s.stopped {
+			return 0
+		}
+		if score > alpha {
+			alpha = score
+			*pv = append([]board.Move{move}, line...)
+		}
+	}
+
+	return alpha
+}
+
+func (s *search) negamax(depth int, ply int, alpha int, beta int, pv *[]board.Move) int {
+	if depth <= 0 {
+		return s.quiescence(ply, alpha, beta)
+	}
+	if s.shouldStop() {
+		return 0
+	}
+	s.nodes++
+	if s.isDraw() {
+		return 0
+	}
+
+	hash := s.board.Hash
+	hashMove := uint16(0)
+	if score, ttDepth, bound, move, ok := s.tt.Probe(hash, ply); ok {
+		hashMove = move
+		if ttDepth >= depth {
+			switch {
+			case bound == EXACT:
+				return score
+			case bound == LOWER && score >= beta:
+				return beta
+			case bound == UPPER && score <= alpha:
+				return alpha
+			}
+		}
+	}
+
+	moves := appendMoves(nil, s.board, true)
+	if len(moves) == 0 {
+		if s.board.InCheck() {
+			return -MateScore + ply
+		}
+		return 0
+	}
+	if s.board.HalfTurns >= 100 {
+		// the mate above still counts on the last move the fifty-move rule allows
+		return 0
+	}
+	s.orderMoves(moves, hashMove)
+
+	bound := UPPER
+	bestMove := uint16(0)
+	for _, move := range moves {
+		var line []board.Move
+		undo := s.makeMove(move)
+		score := -s.negamax(depth-1, ply+1, -beta, -alpha, &line)
+		s.unmakeMove(undo)
+		if s.stopped {
+			return 0
+		}
+		if score >= beta {
+			s.tt.Store(hash, ply, depth, LOWER, beta, packMove(move))
+			return beta
+		}
+		if score > alpha {
+			alpha = score
+			bound = EXACT
+			bestMove = packMove(move)
+			*pv = append([]board.Move{move}, line...)
+		}
+	}
+	s.tt.Store(hash, ply, depth, bound, alpha, bestMove)
+
+	return alpha
+}
+
+// quiescence only follows captures and promotions until the position is quiet, so that the
+// evaluation is not fooled by a piece that is about to be taken.
+func (s *search) quiescence(ply int, alpha int, beta int) int {
+	if s.shouldStop() {
+		return 0
+	}
+	s.nodes++
+
+	moves := appendMoves(nil, s.board, true)
+	if len(moves) == 0 {
+		if s.board.InCheck() {
+			return -MateScore + ply
+		}
+		return 0
+	}
+
+	standPat := evaluate(s.board)
+	if standPat >= beta || ply >= maxDepth {
+		return standPat
+	}
+	if standPat > alpha {
+		alpha = standPat
+	}
+
+	s.orderMoves(moves, 0)
+	for _, move := range moves {
+		if !move.IsCapture && move.Promotion == board.PAWN {
+			continue
+		}
+		undo := s.makeMove(move)
+		score := -s.quiescence(ply+1, -beta, -alpha)
+		s.unmakeMove(undo)
+		if s.stopped {
+			return 0
+		}
+		if score >= beta {
+			return beta
+		}
+		if score > alpha {
+			alpha = score
+		}
+	}
+
+	return alpha
+}
+
+// makeMove returns the position before the move, to be restored by unmakeMove.
+func (s *search) makeMove(move board.Move) board.Bitboards {
+	undo := *s.board
+	s.board.MakeMove(move)
+	s.history = append(s.history, s.board.Hash)
+
+	return undo
+}
+
+func (s *search) unmakeMove(undo board.Bitboards) {
+	s.history = s.history[:len(s.history)-1]
+	*s.board = undo
+}
+
+// isDraw recognizes repetitions and positions in which no side can mate. A position repeated
+// within the search counts as a draw right away, as the line could be repeated again, while
+// positions of the game have to occur three times.
+func (s *search) isDraw() bool {
+	if insufficientMaterial(s.board) {
+		return true
+	}
+
+	hash := s.board.Hash
+	last := len(s.history) - 1
+	count := 1
+	for i := last - 2; i >= 0 && i >= last-s.board.HalfTurns; i -= 2 {
+		if s.history[i] != hash {
+			continue
+		}
+		count++
+		if i >= s.root || count >= 3 {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *search) shouldStop() bool {
+	if s.stopped {
+		return true
+	}
+	if s.limits.Nodes > 0 && s.nodes >= s.limits.Nodes {
+		s.abort("node limit reached")
+	}
+	// looking at the clock and the signals is expensive, so we only do it every now and then
+	if s.nodes > 0 && s.nodes%1024 == 0 {
+		s.poll()
+		if !s.pondering && s.hardTime > 0 && s.elapsed() >= s.hardTime {
+			s.abort("hard time limit reached")
+		}
+	}
+
+	return s.stopped
+}
+
+// abort stops the search. The reason of the first abort is kept.
+func (s *search) abort(reason string) {
+	if !s.stopped {
+		s.stopped = true
+		s.stopReason = reason
+	}
+}
+
+// poll looks for stop and ponderhit without waiting for them.
+func (s *search) poll() {
+	select {
+	case <-s.stop:
+		s.abort("stopped by the GUI")
+	default:
+	}
+
+	if s.pondering {
+		select {
+		case <-s.ponderhit:
+			// the opponent played the expected move, from now on it is our time we are thinking on
+			s.pondering = false
+			s.start = s.clock.Now()
+		default:
+		}
+	}
+}
+
+func (s *search) elapsed() time.Duration {
+	return s.clock.Now().Sub(s.start)
+}
+
+// orderMoves puts the best move known from earlier searches first, followed by captures of
+// valuable pieces with cheap ones, promotions and the quiet moves.
+func (s *search) orderMoves(moves []board.Move, bestMove uint16) {
+	scores := make(map[board.Move]int, len(moves))
+	for _, move := range moves {
+		score := 0
+		if bestMove != 0 && packMove(move) == bestMove {
+			score = infinity
+		}
+		if move.IsCapture {
+			kind, _, _ := s.board.PieceAt(move.From.Index())
+			score += 10*pieceValues[move.Captured] - pieceValues[kind]
+		}
+		if move.Promotion != board.PAWN {
+			score += pieceValues[move.Promotion]
+		}
+		scores[move] = score
+	}
+
+	sort.SliceStable(moves, func(a int, b int) bool {
+		return scores[moves[a]] > scores[moves[b]]
+	})
+}
+
+func (s *search) info(result SearchResult) *uci.Message {
+	var pv []string
+	for _, move := range result.PV {
+		pv = append(pv, move.String())
+	}
+
+	return &uci.Message{Kind: uci.InfoMessageKind, Info: &uci.InfoMessage{
+		Kinds:    []uci.InfoKind{uci.Info_depthKind, uci.Info_scoreKind, uci.Info_nodesKind, uci.Info_timeKind, uci.Info_hashFullKind, uci.Info_pvKind},
+		Depth:    result.Depth,
+		Score:    uciScore(result.Score),
+		Nodes:    result.Nodes,
+		Time:     int(s.elapsed().Milliseconds()),
+		HashFull: s.tt.Hashfull(),
+		PV:       pv,
+	}}
+}
+
+// uciScore converts the score as expected by UCI: mates in moves, everything else in centipawns.
+func uciScore(score int) uci.Score {
+	if score >= MateScore-maxDepth {
+		return uci.Score{IsMate: true, Mate: (MateScore - score + 1) / 2}
+	}
+	if score <= -MateScore+maxDepth {
+		return uci.Score{IsMate: true, Mate: -(MateScore + score) / 2}
+	}
+
+	return uci.Score{Centipawns: score}
+}
+
+// pieceValues are only used to order the moves
+var pieceValues = [6]int{100, 320, 330, 500, 900, 0}
@@ -0,0 +1,143 @@
+package engine
+
+import (
+	"bytes"
+	"chessBot/board"
+	"chessBot/fen"
+	"chessBot/uci"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSearchFindsMate(t *testing.T) {
+	for _, testCase := range []struct {
+		desc     string
+		fen      string
+		depth    int
+		bestMove string
+		score    int
+	}{
+		{
+			desc:     "back rank mate",
+			fen:      "6k1/5ppp/8/8/8/8/8/R5K1 w - - 0 1",
+			depth:    3,
+			bestMove: "a1a8",
+			score:    MateScore - 1,
+		},
+		{
+			desc:  "ladder mate in two",
+			fen:   "7k/8/8/8/8/8/R7/1R4K1 w - - 0 1",
+			depth: 4,
+			score: MateScore - 3,
+		},
+	} {
+		e := engineWithFen(testCase.fen)
+		result := e.Search(SearchLimits{Depth: testCase.depth})
+
+		if testCase.bestMove != "" && result.BestMove.String() != testCase.bestMove {
+			t.Errorf("%s: expected %s, but got %s", testCase.desc, testCase.bestMove, result.BestMove)
+		}
+		if result.Score != testCase.score {
+			t.Errorf("%s: expected score %d, but got %d", testCase.desc, testCase.score, result.Score)
+		}
+	}
+}
+
+func TestSearchWinsMaterial(t *testing.T) {
+	e := engineWithFen("4k3/8/8/3q4/8/8/3R4/4K3 w - - 0 1")
+	result := e.Search(SearchLimits{Depth: 2})
+
+	if result.BestMove.String() != "d2d5" {
+		t.Error("expected the hanging queen to be taken, but got", result.BestMove)
+	}
+	if len(result.PV) == 0 || result.PV[0] != result.BestMove {
+		t.Error("expected the pv to start with the best move, but got", result.PV)
+	}
+}
+
+func TestSearchWithoutLegalMoves(t *testing.T) {
+	for _, testCase := range []struct {
+		desc  string
+		fen   string
+		score int
+	}{
+		{
+			desc:  "checkmate",
+			fen:   "R5k1/5ppp/8/8/8/8/8/6K1 b - - 0 1",
+			score: -MateScore,
+		},
+		{
+			desc:  "stalemate",
+			fen:   "7k/5Q2/6K1/8/8/8/8/8 b - - 0 1",
+			score: 0,
+		},
+	} {
+		e := engineWithFen(testCase.fen)
+		result := e.Search(SearchLimits{Depth: 3})
+
+		if !result.BestMove.IsNull() {
+			t.Errorf("%s: expected the null move, but got %s", testCase.desc, result.BestMove)
+		}
+		if result.Score != testCase.score {
+			t.Errorf("%s: expected score %d, but got %d", testCase.desc, testCase.score, result.Score)
+		}
+	}
+}
+
+func TestSearchLimits(t *testing.T) {
+	e := engineWithFen(fen.STARTPOSFEN)
+	before := e.Board.String()
+
+	result := e.Search(SearchLimits{Depth: 3})
+	if result.Depth != 3 {
+		t.Error("expected depth 3, but got", result.Depth)
+	}
+	if e.Board.String() != before {
+		t.Error("expected the board to be unchanged after the search")
+	}
+
+	result = e.Search(SearchLimits{Nodes: 500})
+	if result.Nodes > 500 {
+		t.Error("expected at most 500 nodes, but got", result.Nodes)
+	}
+
+	start := time.Now()
+	e.Search(SearchLimits{MoveTime: 50 * time.Millisecond})
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Error("expected the search to stop after 50ms, but it took", elapsed)
+	}
+
+	result = e.Search(SearchLimits{Depth: 2, SearchMoves: []board.Move{board.MoveFromString("a2a3")}})
+	if result.BestMove.String() != "a2a3" {
+		t.Error("expected the only search move a2a3, but got", result.BestMove)
+	}
+}
+
+func TestSearchSendsInfo(t *testing.T) {
+	var output bytes.Buffer
+	e := NewEngine(Config{Output: &output})
+	e.Board, _ = fen.FenToBoard("6k1/5ppp/8/8/8/8/8/R5K1 w - - 0 1")
+
+	e.Search(SearchLimits{Depth: 2})
+
+	lines := strings.Split(strings.TrimSpace(output.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatal("expected one info line, but got", lines)
+	}
+	if !strings.HasPrefix(lines[0], "info depth 1 score mate 1 nodes ") || !strings.HasSuffix(lines[0], " pv a1a8") {
+		t.Error("unexpected info line", lines[0])
+	}
+}
+
+func TestLimitsFromGoStatement(t *testing.T) {
+	limits := LimitsFromGoStatement(&uci.GoStatement{Depth: 7, MoveTime: 1500, SearchMoves: []string{"e2e4", "d2d4"}})
+	if limits.Depth != 7 || limits.MoveTime != 1500*time.Millisecond || len(limits.SearchMoves) != 2 {
+		t.Errorf("unexpected limits %+v", limits)
+	}
+
+	limits = LimitsFromGoStatement(&uci.GoStatement{})
+	if limits.Depth != defaultDepth {
+		t.Error("expected the default depth without limits, but got", limits.Depth)
+	}
+}
@@ -0,0 +1,105 @@
+package engine
+
+import "chessBot/board"
+
+// GameStatus tells whether a game is over and why.
+type GameStatus int
+
+const (
+	ONGOING GameStatus = iota
+	CHECKMATE
+	STALEMATE
+	// the draws by threefold repetition and the fifty-move rule have to be claimed, the others end
+	// the game right away
+	THREEFOLD_REPETITION
+	FIVEFOLD_REPETITION
+	FIFTY_MOVE_RULE
+	SEVENTY_FIVE_MOVE_RULE
+	INSUFFICIENT_MATERIAL
+)
+
+var gameStatusNames = []string{
+	"ongoing",
+	"checkmate",
+	"stalemate",
+	"threefold repetition",
+	"fivefold repetition",
+	"fifty-move rule",
+	"seventy-five-move rule",
+	"insufficient material",
+}
+
+func (s GameStatus) String() string {
+	return gameStatusNames[s]
+}
+
+// IsDraw tells whether the status is a draw, including the ones that have to be claimed.
+func (s GameStatus) IsDraw() bool {
+	return s != ONGOING && s != CHECKMATE
+}
+
+// Status tells whether the game is over on the board. The history holds the hashes of the
+// positions before the current one, oldest first, to find repetitions.
+func Status(b *board.Board, history []uint64) GameStatus {
+	bb := board.NewBitboards(b)
+	// a mate ends the game even if it is delivered with the last move the fifty-move rule allows
+	if len(appendMoves(nil, bb, true)) == 0 {
+		if bb.InCheck() {
+			return CHECKMATE
+		}
+		return STALEMATE
+	}
+
+	repetitions := repetitions(bb, history)
+	switch {
+	case repetitions >= 5:
+		return FIVEFOLD_REPETITION
+	case b.HalfTurns >= 150:
+		return SEVENTY_FIVE_MOVE_RULE
+	case insufficientMaterial(bb):
+		return INSUFFICIENT_MATERIAL
+	case repetitions >= 3:
+		return THREEFOLD_REPETITION
+	case b.HalfTurns >= 100:
+		return FIFTY_MOVE_RULE
+	}
+
+	return ONGOING
+}
+
+// Status tells whether the game is over in the current position.
+func (e *Engine) Status() GameStatus {
+	return Status(e.Board, e.history)
+}
+
+// repetitions counts how often the current position occurred, including itself. Only positions
+// since the last capture or pawn move can be the same, and only those with the same side to move.
+func repetitions(bb *board.Bitboards, history []uint64) int {
+	count := 1
+	for i := len(history) - 2; i >= 0 && i >= len(history)-bb.HalfTurns; i -= 2 {
+		if history[i] == bb.Hash {
+			count++
+		}
+	}
+
+	return count
+}
+
+// darkSquares are the squares of the same color as a1
+const darkSquares board.Bitboard = 0xaa55aa55aa55aa55
+
+// insufficientMaterial tells whether neither side can mate anymore: there are no pawns, rooks and
+// queens left, and either only a single knight or bishop, or bishops that all move on squares
+// of the same color.
+func insufficientMaterial(bb *board.Bitboards) bool {
+	var knights, bishops board.Bitboard
+	for _, pieces := range bb.Pieces {
+		if pieces[board.PAWN]|pieces[board.ROOK]|pieces[board.QUEEN] != 0 {
+			return false
+		}
+		knights |= pieces[board.KNIGHT]
+		bishops |= pieces[board.BISHOP]
+	}
+
+	return (knights|bishops).Count() <= 1 || knights == 0 && (bishops&darkSquares == 0 || bishops&^darkSquares == 0)
+}
@@ -0,0 +1,136 @@
+package engine
+
+import (
+	"chessBot/board"
+	"chessBot/fen"
+	"chessBot/uci"
+	"strings"
+	"testing"
+)
+
+func TestStatus(t *testing.T) {
+	for _, testCase := range []struct {
+		fen      string
+		expected GameStatus
+	}{
+		{fen.STARTPOSFEN, ONGOING},
+		{"rnb1kbnr/pppp1ppp/8/4p3/6Pq/5P2/PPPPP2P/RNBQKBNR w KQkq - 1 3", CHECKMATE},
+		{"7k/5Q2/6K1/8/8/8/8/8 b - - 0 1", STALEMATE},
+		{"4k3/8/8/8/8/8/8/R3K3 w - - 100 80", FIFTY_MOVE_RULE},
+		{"4k3/8/8/8/8/8/8/R3K3 w - - 150 80", SEVENTY_FIVE_MOVE_RULE},
+		{"4k3/R7/4K3/8/8/8/8/8 b - - 0 1", ONGOING},
+		{"R3k3/8/4K3/8/8/8/8/8 b - - 100 80", CHECKMATE},
+		{"4k3/8/8/8/8/8/8/4K3 w - - 0 1", INSUFFICIENT_MATERIAL},
+		{"4k3/8/8/8/8/8/8/3BK3 w - - 0 1", INSUFFICIENT_MATERIAL},
+		{"4k3/8/8/8/8/8/8/3NK3 b - - 0 1", INSUFFICIENT_MATERIAL},
+		// all bishops on light squares
+		{"2b1k3/8/8/8/8/8/8/3BKB2 w - - 0 1", INSUFFICIENT_MATERIAL},
+		// bishops on squares of both colors
+		{"4k1b1/8/8/8/8/8/8/2B1K3 w - - 0 1", ONGOING},
+		{"4k3/8/8/8/8/8/8/2NNK3 w - - 0 1", ONGOING},
+		{"4k3/8/8/8/8/8/8/2BNK3 w - - 0 1", ONGOING},
+		{"4k3/8/8/8/8/8/4P3/4K3 w - - 0 1", ONGOING},
+	} {
+		b, err := fen.FenToBoard(testCase.fen)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if status := Status(b, nil); status != testCase.expected {
+			t.Errorf("%s: expected %s, but got %s", testCase.fen, testCase.expected, status)
+		}
+	}
+}
+
+func TestStatusRepetition(t *testing.T) {
+	knightDance := []string{"g1f3", "g8f6", "f3g1", "f6g8"}
+	for _, testCase := range []struct {
+		repetitions int
+		expected    GameStatus
+	}{
+		{1, ONGOING},
+		{2, THREEFOLD_REPETITION},
+		{4, FIVEFOLD_REPETITION},
+	} {
+		var moves []string
+		for i := 0; i < testCase.repetitions; i++ {
+			moves = append(moves, knightDance...)
+		}
+
+		e := NewEngine(Config{})
+		if err := e.InitBoard(&uci.PositionStatement{IsStartPos: true, Moves: moves}); err != nil {
+			t.Fatal(err)
+		}
+		if status := e.Status(); status != testCase.expected {
+			t.Errorf("%s: expected %s, but got %s", strings.Join(moves, " "), testCase.expected, status)
+		}
+	}
+
+	// a pawn move makes the earlier positions impossible to reach again
+	e := NewEngine(Config{})
+	e.InitBoard(&uci.PositionStatement{IsStartPos: true, Moves: append(append([]string{"e2e4", "e7e5"}, knightDance...), knightDance...)})
+	if status := e.Status(); status != THREEFOLD_REPETITION {
+		t.Error("expected threefold repetition after the pawn moves, but got", status)
+	}
+
+	// GUIs send the en passant square after every double step, even if no pawn can capture on it
+	e = NewEngine(Config{})
+	e.InitBoard(&uci.PositionStatement{
+		IsFen:     true,
+		FenString: "rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1",
+		Moves:     []string{"g8f6", "g1f3", "f6g8", "f3g1", "g8f6", "g1f3", "f6g8", "f3g1"},
+	})
+	if status := e.Status(); status != THREEFOLD_REPETITION {
+		t.Error("expected threefold repetition with the en passant square in the fen, but got", status)
+	}
+}
+
+func TestSearchScoresDraws(t *testing.T) {
+	for _, fenString := range []string{
+		// a bishop is more material, but cannot mate
+		"4k3/8/8/8/8/8/8/3BK3 w - - 0 1",
+		// every move ends the game by the fifty-move rule
+		"4k3/8/8/8/8/8/8/R3K3 w - - 99 80",
+	} {
+		e := engineWithFen(fenString)
+		if result := e.Search(SearchLimits{Depth: 3}); result.Score != 0 {
+			t.Errorf("%s: expected a draw, but got %d", fenString, result.Score)
+		}
+	}
+}
+
+func TestSearchRecognizesRepetitions(t *testing.T) {
+	e := NewEngine(Config{})
+	e.InitBoard(&uci.PositionStatement{IsStartPos: true, Moves: []string{"g1f3", "g8f6", "f3g1", "f6g8", "g1f3", "g8f6", "f3g1"}})
+
+	s := &search{board: board.NewBitboards(e.Board), history: append(e.history, e.Board.Hash())}
+	s.root = len(s.history) - 1
+	s.makeMove(moveFromString(t, s, "f6g8"))
+	if !s.isDraw() {
+		t.Error("expected the third occurrence of the start position to be a draw")
+	}
+
+	// within the search a single repetition is enough
+	e.InitBoard(&uci.PositionStatement{IsStartPos: true})
+	s = &search{board: board.NewBitboards(e.Board), history: []uint64{e.Board.Hash()}}
+	for _, move := range []string{"g1f3", "g8f6", "f3g1"} {
+		s.makeMove(moveFromString(t, s, move))
+		if s.isDraw() {
+			t.Error("expected no draw after", move)
+		}
+	}
+	s.makeMove(moveFromString(t, s, "f6g8"))
+	if !s.isDraw() {
+		t.Error("expected the repetition within the search to be a draw")
+	}
+}
+
+func moveFromString(t *testing.T, s *search, moveString string) board.Move {
+	for _, move := range appendMoves(nil, s.board, true) {
+		if move.String() == moveString {
+			return move
+		}
+	}
+	t.Fatal("illegal move", moveString)
+
+	return board.NullMove
+}
@@ -0,0 +1,80 @@
+package engine
+
+import (
+	"chessBot/board"
+	"time"
+)
+
+// Clock tells the time. Tests replace it, so that they do not have to wait for time to pass.
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+const (
+	// moveOverhead is kept back for the communication with the GUI, so that we never lose on time.
+	moveOverhead = 50 * time.Millisecond
+	// defaultMovesToGo is assumed for the rest of the game if the time control does not say otherwise.
+	defaultMovesToGo = 30
+	minimumTime      = time.Millisecond
+)
+
+// allocateTime decides how long to think. No new iteration is started after the soft limit, and
+// the search is aborted at the hard limit. Both are zero if the time is not limited, which is only
+// the case if the go command sets neither move time nor clocks.
+func allocateTime(limits SearchLimits, side board.Color) (soft time.Duration, hard time.Duration) {
+	if limits.MoveTime > 0 {
+		// the clock is only looked at every now and then, so we stop a little early
+		moveTime := limits.MoveTime - moveOverhead
+		if moveTime < minimumTime {
+			moveTime = minimumTime
+		}
+		return moveTime, moveTime
+	}
+	if limits.WhiteTime == 0 && limits.BlackTime == 0 && limits.WhiteIncrement == 0 && limits.BlackIncrement == 0 {
+		return 0, 0
+	}
+
+	remaining, increment := limits.WhiteTime, limits.WhiteIncrement
+	if side == board.BLACK {
+		remaining, increment = limits.BlackTime, limits.BlackIncrement
+	}
+	if remaining <= 0 {
+		// our clock ran out already, so we move at once, or live on the increment
+		hard = minimumTime
+		if increment*3/4 > hard {
+			hard = increment * 3 / 4
+		}
+		return hard, hard
+	}
+
+	movesToGo := limits.MovesToGo
+	if movesToGo <= 0 || movesToGo > defaultMovesToGo {
+		movesToGo = defaultMovesToGo
+	}
+
+	available := remaining - moveOverhead
+	if available < minimumTime {
+		return minimumTime, minimumTime
+	}
+
+	soft = available/time.Duration(movesToGo) + increment*3/4
+	hard = soft * 4
+	// whatever happens, some time has to be left for the next moves
+	if maxHard := available * 3 / 4; hard > maxHard {
+		hard = maxHard
+	}
+	if soft > hard {
+		soft = hard
+	}
+	if hard < minimumTime {
+		soft, hard = minimumTime, minimumTime
+	}
+
+	return soft, hard
+}
@@ -0,0 +1,144 @@
+package engine
+
+import (
+	"chessBot/board"
+	"chessBot/fen"
+	"chessBot/uci"
+	"testing"
+	"time"
+)
+
+// fakeClock moves on by step whenever it is asked for the time.
+type fakeClock struct {
+	now  time.Time
+	step time.Duration
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.now = c.now.Add(c.step)
+	return c.now
+}
+
+func TestAllocateTime(t *testing.T) {
+	for _, testCase := range []struct {
+		desc   string
+		limits SearchLimits
+		side   board.Color
+		soft   time.Duration
+		hard   time.Duration
+	}{
+		{
+			desc:   "unlimited",
+			limits: SearchLimits{},
+			side:   board.WHITE,
+		},
+		{
+			desc:   "move time",
+			limits: SearchLimits{MoveTime: 2 * time.Second, WhiteTime: time.Minute},
+			side:   board.WHITE,
+			soft:   2*time.Second - moveOverhead,
+			hard:   2*time.Second - moveOverhead,
+		},
+		{
+			desc:   "move time shorter than the overhead",
+			limits: SearchLimits{MoveTime: 10 * time.Millisecond},
+			side:   board.WHITE,
+			soft:   time.Millisecond,
+			hard:   time.Millisecond,
+		},
+		{
+			desc:   "sudden death",
+			limits: SearchLimits{WhiteTime: time.Minute, BlackTime: time.Second},
+			side:   board.WHITE,
+			soft:   1998333333,
+			hard:   7993333332,
+		},
+		{
+			desc:   "black uses its own clock and increment",
+			limits: SearchLimits{WhiteTime: time.Second, BlackTime: 3 * time.Second, WhiteIncrement: time.Second, BlackIncrement: 2 * time.Second},
+			side:   board.BLACK,
+			soft:   1598333333,
+			hard:   2212500000,
+		},
+		{
+			desc:   "last move before the time control",
+			limits: SearchLimits{WhiteTime: time.Second, MovesToGo: 1},
+			side:   board.WHITE,
+			soft:   712500000,
+			hard:   712500000,
+		},
+		{
+			desc:   "almost flagging",
+			limits: SearchLimits{BlackTime: 30 * time.Millisecond},
+			side:   board.BLACK,
+			soft:   time.Millisecond,
+			hard:   time.Millisecond,
+		},
+		{
+			desc:   "own clock at zero",
+			limits: SearchLimits{WhiteTime: 0, BlackTime: 5 * time.Second},
+			side:   board.WHITE,
+			soft:   time.Millisecond,
+			hard:   time.Millisecond,
+		},
+		{
+			desc:   "own clock below zero with increment",
+			limits: SearchLimits{WhiteTime: -100 * time.Millisecond, BlackTime: 5 * time.Second, WhiteIncrement: time.Second},
+			side:   board.WHITE,
+			soft:   750 * time.Millisecond,
+			hard:   750 * time.Millisecond,
+		},
+	} {
+		soft, hard := allocateTime(testCase.limits, testCase.side)
+		if soft != testCase.soft || hard != testCase.hard {
+			t.Errorf("%s: expected %s and %s, but got %s and %s", testCase.desc, testCase.soft, testCase.hard, soft, hard)
+		}
+		if hard > 0 && testCase.limits.MoveTime == 0 {
+			remaining := testCase.limits.WhiteTime
+			if testCase.side == board.BLACK {
+				remaining = testCase.limits.BlackTime
+			}
+			if remaining > 0 && hard >= remaining {
+				t.Errorf("%s: expected to keep time on the clock, but would use %s of %s", testCase.desc, hard, remaining)
+			}
+		}
+	}
+}
+
+func TestSearchStopsAtSoftLimit(t *testing.T) {
+	e := NewEngine(Config{Clock: &fakeClock{step: time.Hour}})
+	e.Board, _ = fen.FenToBoard(fen.STARTPOSFEN)
+
+	result := e.Search(SearchLimits{WhiteTime: time.Minute})
+	if result.Depth != 1 {
+		t.Error("expected no second iteration after the soft limit, but got depth", result.Depth)
+	}
+}
+
+func TestSearchAbortsAtHardLimit(t *testing.T) {
+	clock := &fakeClock{step: 100 * time.Millisecond}
+	e := NewEngine(Config{Clock: clock})
+	e.Board, _ = fen.FenToBoard(fen.STARTPOSFEN)
+	start := clock.now
+
+	result := e.Search(SearchLimits{WhiteTime: time.Minute, Depth: maxDepth})
+
+	_, hard := allocateTime(SearchLimits{WhiteTime: time.Minute}, board.WHITE)
+	if used := clock.now.Sub(start); used > hard+2*clock.step {
+		t.Errorf("expected to stop at %s, but used %s", hard, used)
+	}
+	if result.BestMove.IsNull() {
+		t.Error("expected a best move from the completed iterations")
+	}
+}
+
+func TestLimitsWithClocks(t *testing.T) {
+	limits := LimitsFromGoStatement(&uci.GoStatement{Wtime: 1000, Btime: 2000, Winc: 10, Binc: 20, MovesToGo: 5})
+	if limits.WhiteTime != time.Second || limits.BlackTime != 2*time.Second ||
+		limits.WhiteIncrement != 10*time.Millisecond || limits.BlackIncrement != 20*time.Millisecond || limits.MovesToGo != 5 {
+		t.Errorf("unexpected limits %+v", limits)
+	}
+	if limits.Depth != 0 {
+		t.Error("expected no default depth with time on the clock, but got", limits.Depth)
+	}
+}
@@ -0,0 +1,155 @@
+package engine
+
+import (
+	"chessBot/board"
+	"unsafe"
+)
+
+// Bound tells how the score of a transposition table entry relates to the real score.
+type Bound uint8
+
+const (
+	NO_BOUND Bound = iota
+	EXACT
+	// LOWER means the real score is at least as high, the search failed high.
+	LOWER
+	// UPPER means the real score is at most as high, the search failed low.
+	UPPER
+)
+
+const (
+	DefaultHashSize = 16
+	MinHashSize     = 1
+	MaxHashSize     = 1024
+)
+
+type ttEntry struct {
+	hash  uint64
+	score int32
+	move  uint16
+	depth int8
+	bound Bound
+	age   uint8
+}
+
+// TranspositionTable remembers the results of searched positions by their zobrist hash, so that
+// positions reached by different move orders are only searched once. It is not safe for
+// concurrent use.
+type TranspositionTable struct {
+	entries []ttEntry
+	age     uint8
+}
+
+// NewTranspositionTable creates a table of the given size in megabytes.
+func NewTranspositionTable(sizeInMB int) *TranspositionTable {
+	tt := &TranspositionTable{}
+	tt.Resize(sizeInMB)
+
+	return tt
+}
+
+// Resize drops all entries and changes the size to the given number of megabytes.
+func (tt *TranspositionTable) Resize(sizeInMB int) {
+	if sizeInMB < MinHashSize {
+		sizeInMB = MinHashSize
+	}
+	if sizeInMB > MaxHashSize {
+		sizeInMB = MaxHashSize
+	}
+	tt.entries = make([]ttEntry, sizeInMB*1024*1024/int(unsafe.Sizeof(ttEntry{})))
+	tt.age = 0
+}
+
+func (tt *TranspositionTable) Clear() {
+	for i := range tt.entries {
+		tt.entries[i] = ttEntry{}
+	}
+	tt.age = 0
+}
+
+// NewSearch marks all entries as older than the ones of the coming search.
+func (tt *TranspositionTable) NewSearch() {
+	tt.age++
+}
+
+// Hashfull returns how many of a thousand entries are used by the current search.
+func (tt *TranspositionTable) Hashfull() int {
+	samples := 1000
+	if len(tt.entries) < samples {
+		samples = len(tt.entries)
+	}
+
+	used := 0
+	for _, entry := range tt.entries[:samples] {
+		if entry.bound != NO_BOUND && entry.age == tt.age {
+			used++
+		}
+	}
+
+	return used * 1000 / samples
+}
+
+// Probe looks up the position. The score is adjusted to mates counted from ply.
+func (tt *TranspositionTable) Probe(hash uint64, ply int) (score int, depth int, bound Bound, move uint16, ok bool) {
+	entry := &tt.entries[hash%uint64(len(tt.entries))]
+	if entry.bound == NO_BOUND || entry.hash != hash {
+		return 0, 0, NO_BOUND, 0, false
+	}
+
+	return scoreFromTT(int(entry.score), ply), int(entry.depth), entry.bound, entry.move, true
+}
+
+// Store remembers the result of searching the position to depth at ply. Entries of earlier
+// searches and of shallower depths are replaced.
+func (tt *TranspositionTable) Store(hash uint64, ply int, depth int, bound Bound, score int, move uint16) {
+	entry := &tt.entries[hash%uint64(len(tt.entries))]
+	if entry.bound != NO_BOUND && entry.hash == hash && entry.age == tt.age && int(entry.depth) > depth {
+		return
+	}
+	if move == 0 && entry.hash == hash {
+		// keep the move we know, it is better than none for ordering the moves
+		move = entry.move
+	}
+
+	*entry = ttEntry{
+		hash:  hash,
+		score: int32(scoreToTT(score, ply)),
+		move:  move,
+		depth: int8(depth),
+		bound: bound,
+		age:   tt.age,
+	}
+}
+
+// Mate scores count the plies from the root. In the table they are counted from the position
+// itself, as it can be reached at a different ply later.
+func scoreToTT(score int, ply int) int {
+	if score >= MateScore-maxDepth {
+		return score + ply
+	}
+	if score <= -MateScore+maxDepth {
+		return score - ply
+	}
+
+	return score
+}
+
+func scoreFromTT(score int, ply int) int {
+	if score >= MateScore-maxDepth {
+		return score - ply
+	}
+	if score <= -MateScore+maxDepth {
+		return score + ply
+	}
+
+	return score
+}
+
+// packMove squeezes the move into 16 bits: six for each position and the promotion.
+func packMove(move board.Move) uint16 {
+	if move.IsNull() {
+		return 0
+	}
+
+	return uint16(move.From.Index()) | uint16(move.To.Index())<<6 | uint16(move.Promotion)<<12
+}
@@ -0,0 +1,152 @@
+package engine
+
+import (
+	"chessBot/board"
+	"chessBot/fen"
+	"testing"
+	"unsafe"
+)
+
+func TestTranspositionTableStoreAndProbe(t *testing.T) {
+	tt := NewTranspositionTable(1)
+	move := packMove(board.MoveFromString("e2e4"))
+
+	if _, _, _, _, ok := tt.Probe(42, 0); ok {
+		t.Error("expected an empty table")
+	}
+
+	tt.Store(42, 3, 5, LOWER, 120, move)
+	score, depth, bound, storedMove, ok := tt.Probe(42, 3)
+	if !ok || score != 120 || depth != 5 || bound != LOWER || storedMove != move {
+		t.Errorf("unexpected entry %d %d %d %d %t", score, depth, bound, storedMove, ok)
+	}
+
+	// another position in the same slot
+	other := 42 + uint64(len(tt.entries))
+	if _, _, _, _, ok := tt.Probe(other, 3); ok {
+		t.Error("expected no entry for another hash")
+	}
+}
+
+func TestTranspositionTableReplacement(t *testing.T) {
+	tt := NewTranspositionTable(1)
+	move := packMove(board.MoveFromString("e2e4"))
+
+	tt.Store(7, 0, 6, EXACT, 10, move)
+	tt.Store(7, 0, 2, EXACT, 20, 0)
+	if score, depth, _, _, _ := tt.Probe(7, 0); score != 10 || depth != 6 {
+		t.Error("expected the deeper entry to be kept")
+	}
+
+	tt.NewSearch()
+	tt.Store(7, 0, 2, UPPER, 20, 0)
+	score, depth, _, storedMove, _ := tt.Probe(7, 0)
+	if score != 20 || depth != 2 {
+		t.Error("expected entries of earlier searches to be replaced")
+	}
+	if storedMove != move {
+		t.Error("expected the known move of the position to be kept")
+	}
+}
+
+func TestTranspositionTableMateScores(t *testing.T) {
+	tt := NewTranspositionTable(1)
+
+	// mate in 3 plies found at ply 4 is a mate in 7 plies from the root
+	tt.Store(1, 4, 3, EXACT, MateScore-7, 0)
+	if score, _, _, _, _ := tt.Probe(1, 2); score != MateScore-5 {
+		t.Error("expected the mate to be 5 plies from the root when reached at ply 2, but got", MateScore-score)
+	}
+
+	tt.Store(2, 4, 3, EXACT, -MateScore+6, 0)
+	if score, _, _, _, _ := tt.Probe(2, 6); score != -MateScore+8 {
+		t.Error("expected to be mated 8 plies from the root when reached at ply 6, but got", MateScore+score)
+	}
+}
+
+func TestTranspositionTableSize(t *testing.T) {
+	tt := NewTranspositionTable(2)
+	if size := len(tt.entries) * int(unsafe.Sizeof(ttEntry{})); size > 2*1024*1024 || size < 2*1024*1024-64 {
+		t.Error("expected about 2MB, but got", size)
+	}
+
+	tt.Store(1, 0, 1, EXACT, 0, 0)
+	tt.Resize(4)
+	if _, _, _, _, ok := tt.Probe(1, 0); ok {
+		t.Error("expected resizing to clear the table")
+	}
+	if len(tt.entries) != 2*len(NewTranspositionTable(2).entries) {
+		t.Error("expected the table to grow")
+	}
+}
+
+func TestTranspositionTableHashfull(t *testing.T) {
+	tt := NewTranspositionTable(1)
+	for i := uint64(0); i < 500; i++ {
+		tt.Store(i, 0, 1, EXACT, 0, 0)
+	}
+	if tt.Hashfull() != 500 {
+		t.Error("expected half of the table to be used, but got", tt.Hashfull())
+	}
+
+	tt.NewSearch()
+	if tt.Hashfull() != 0 {
+		t.Error("expected entries of earlier searches not to count, but got", tt.Hashfull())
+	}
+
+	tt.Store(3, 0, 1, EXACT, 0, 0)
+	tt.Clear()
+	if tt.Hashfull() != 0 {
+		t.Error("expected an empty table after clearing it")
+	}
+}
+
+func TestSearchWithTranspositionTable(t *testing.T) {
+	e := NewEngine(Config{HashSize: 1})
+	e.Board, _ = fen.FenToBoard("r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1")
+	first := e.Search(SearchLimits{Depth: 3})
+	if e.tt.Hashfull() == 0 {
+		t.Error("expected the search to fill the table")
+	}
+
+	// the second search profits from the first one
+	second := e.Search(SearchLimits{Depth: 3})
+	if second.Nodes >= first.Nodes {
+		t.Errorf("expected less than %d nodes, but got %d", first.Nodes, second.Nodes)
+	}
+	if second.Score != first.Score {
+		t.Errorf("expected the same score %d, but got %d", first.Score, second.Score)
+	}
+}
+
+func TestHashOptions(t *testing.T) {
+	e := NewEngine(Config{})
+	e.Board, _ = fen.FenToBoard(fen.STARTPOSFEN)
+
+	e.handleLine("setoption name Hash value 2\n")
+	if len(e.tt.entries) != len(NewTranspositionTable(2).entries) {
+		t.Error("expected the table to be resized to 2MB")
+	}
+
+	e.handleLine("setoption name Hash value 100000\n")
+	if len(e.tt.entries) != len(NewTranspositionTable(2).entries) {
+		t.Error("expected invalid sizes to be ignored")
+	}
+
+	e.Search(SearchLimits{Depth: 2})
+	e.handleLine("setoption name Clear Hash\n")
+	if e.tt.Hashfull() != 0 {
+		t.Error("expected Clear Hash to clear the table")
+	}
+
+	e.Search(SearchLimits{Depth: 2})
+	e.handleLine("ucinewgame\n")
+	if e.tt.Hashfull() != 0 {
+		t.Error("expected ucinewgame to clear the table")
+	}
+
+	e = NewEngine(Config{HashSize: 2})
+	if len(e.tt.entries) != len(NewTranspositionTable(2).entries) {
+		t.Error("expected the configured size")
+	}
+}
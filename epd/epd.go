@@ -0,0 +1,289 @@
+package epd
+
+import (
+	"bufio"
+	"chessBot"
+	"chessBot/board"
+	"chessBot/fen"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Opcode names an operation of an EPD record.
+type Opcode string
+
+const (
+	ANALYSIS_DEPTH       Opcode = "acd"
+	ANALYSIS_NODES       Opcode = "acn"
+	ANALYSIS_SECONDS     Opcode = "acs"
+	AVOID_MOVE           Opcode = "am"
+	BEST_MOVE            Opcode = "bm"
+	COMMENT              Opcode = "c0"
+	CENTIPAWN_EVALUATION Opcode = "ce"
+	DIRECT_MATE          Opcode = "dm"
+	FULLMOVE_NUMBER      Opcode = "fmvn"
+	HALFMOVE_CLOCK       Opcode = "hmvc"
+	ID                   Opcode = "id"
+	PREDICTED_MOVE       Opcode = "pm"
+	PREDICTED_VARIATION  Opcode = "pv"
+	SUPPLIED_MOVE        Opcode = "sm"
+)
+
+// the operands of these opcodes are moves in SAN, all of them are played from the position of the
+// record except for the predicted variation, which is a line of moves
+var moveOpcodes = map[Opcode]bool{AVOID_MOVE: true, BEST_MOVE: true, PREDICTED_MOVE: true, PREDICTED_VARIATION: true, SUPPLIED_MOVE: true}
+
+// Operation holds the operands of an opcode.
+type Operation struct {
+	Operands []string
+	// Moves are the resolved operands of move opcodes like bm.
+	Moves []board.Move
+}
+
+// Record is a position with its operations.
+type Record struct {
+	Board      *board.Board
+	Operations map[Opcode]Operation
+}
+
+// NewRecord returns a record of the board without any operations.
+func NewRecord(b *board.Board) *Record {
+	return &Record{Board: b, Operations: map[Opcode]Operation{}}
+}
+
+// Moves returns the moves of a move opcode, nil if the record does not have it.
+func (r *Record) Moves(opcode Opcode) []board.Move {
+	return r.Operations[opcode].Moves
+}
+
+// Int returns the operand of an opcode with a single number, like acd or ce.
+func (r *Record) Int(opcode Opcode) (int, bool) {
+	operation, ok := r.Operations[opcode]
+	if !ok || len(operation.Operands) != 1 {
+		return 0, false
+	}
+	n, err := strconv.Atoi(operation.Operands[0])
+
+	return n, err == nil
+}
+
+// Text returns the operands of an opcode as a single string, like the text of id or c0.
+func (r *Record) Text(opcode Opcode) (string, bool) {
+	operation, ok := r.Operations[opcode]
+
+	return strings.Join(operation.Operands, " "), ok
+}
+
+// SetMoves sets a move opcode. The moves have to be legal on the board of the record, or in the case
+// of pv form a legal line. They may come without flags, like the moves of an engine. The record
+// is left as it was if a move is illegal.
+func (r *Record) SetMoves(opcode Opcode, moves ...board.Move) error {
+	operation := Operation{}
+	b := r.Board.Copy()
+	for _, move := range moves {
+		move, err := chessBot.LegalMove(b, move)
+		if err != nil {
+			return fmt.Errorf("%s: %w", opcode, err)
+		}
+		san, err := chessBot.SAN(b, move)
+		if err != nil {
+			return fmt.Errorf("%s: %w", opcode, err)
+		}
+		operation.Moves = append(operation.Moves, move)
+		operation.Operands = append(operation.Operands, san)
+		if opcode == PREDICTED_VARIATION {
+			b.MakeMove(move)
+		}
+	}
+	r.Operations[opcode] = operation
+
+	return nil
+}
+
+func (r *Record) SetInt(opcode Opcode, n int) {
+	r.Operations[opcode] = Operation{Operands: []string{strconv.Itoa(n)}}
+}
+
+func (r *Record) SetText(opcode Opcode, text string) {
+	r.Operations[opcode] = Operation{Operands: []string{text}}
+}
+
+// String writes the record as a single EPD line. The operations are sorted by their opcode.
+func (r *Record) String() string {
+	fields := strings.Split(fen.BoardToFen(r.Board), " ")
+	line := strings.Join(fields[:4], " ")
+
+	var opcodes []string
+	for opcode := range r.Operations {
+		opcodes = append(opcodes, string(opcode))
+	}
+	sort.Strings(opcodes)
+
+	for _, opcode := range opcodes {
+		line += " " + opcode
+		for _, operand := range r.Operations[Opcode(opcode)].Operands {
+			if needsQuotes(Opcode(opcode), operand) {
+				operand = `"` + operand + `"`
+			}
+			line += " " + operand
+		}
+		line += ";"
+	}
+
+	return line
+}
+
+func needsQuotes(opcode Opcode, operand string) bool {
+	if opcode == ID || len(opcode) == 2 && opcode[0] == 'c' && opcode[1] >= '0' && opcode[1] <= '9' {
+		return true
+	}
+
+	return operand == "" || strings.ContainsAny(operand, " \t;\"")
+}
+
+// Parse reads a single EPD line.
+func Parse(line string) (*Record, error) {
+	fields := strings.Fields(line)
+	if len(fields) < 4 {
+		return nil, errors.New("expected four fen fields")
+	}
+	b, err := fen.FenToBoard(strings.Join(fields[:4], " "))
+	if err != nil {
+		return nil, err
+	}
+	record := NewRecord(b)
+
+	// the operations start after the fourth field, they may contain quoted strings with any whitespace
+	rest := line
+	for i := 0; i < 4; i++ {
+		rest = strings.TrimLeft(rest, " \t")
+		rest = rest[len(fields[i]):]
+	}
+	operations, err := splitOperations(rest)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, tokens := range operations {
+		opcode := Opcode(tokens[0])
+		operation := Operation{Operands: tokens[1:]}
+		if moveOpcodes[opcode] {
+			operation.Moves, err = resolveMoves(b, opcode, operation.Operands)
+			if err != nil {
+				return nil, err
+			}
+		}
+		record.Operations[opcode] = operation
+	}
+
+	if n, ok := record.Int(HALFMOVE_CLOCK); ok {
+		b.HalfTurns = n
+	}
+	if n, ok := record.Int(FULLMOVE_NUMBER); ok {
+		b.TurnNumber = n
+	}
+
+	return record, nil
+}
+
+// splitOperations returns the opcode and the operands of each operation.
+func splitOperations(text string) ([][]string, error) {
+	var operations [][]string
+	var tokens []string
+	for i := 0; i < len(text); i++ {
+		switch c := text[i]; {
+		case c == ' ' || c == '\t':
+		case c == ';':
+			if len(tokens) == 0 {
+				return nil, errors.New("operation without opcode")
+			}
+			operations = append(operations, tokens)
+			tokens = nil
+		case c == '"':
+			end := strings.IndexByte(text[i+1:], '"')
+			if end < 0 {
+				return nil, errors.New("unterminated string operand")
+			}
+			if len(tokens) == 0 {
+				return nil, errors.New("string operand without opcode")
+			}
+			tokens = append(tokens, text[i+1:i+1+end])
+			i += end + 1
+		default:
+			end := strings.IndexAny(text[i:], " \t;\"")
+			if end < 0 {
+				end = len(text) - i
+			}
+			tokens = append(tokens, text[i:i+end])
+			i += end - 1
+		}
+	}
+	if len(tokens) > 0 {
+		return nil, fmt.Errorf("operation %s is not terminated by a semicolon", tokens[0])
+	}
+
+	return operations, nil
+}
+
+func resolveMoves(b *board.Board, opcode Opcode, operands []string) ([]board.Move, error) {
+	var moves []board.Move
+	line := b.Copy()
+	for _, operand := range operands {
+		move, err := chessBot.ParseSAN(line, operand)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", opcode, err)
+		}
+		moves = append(moves, move)
+		if opcode == PREDICTED_VARIATION {
+			line.MakeMove(move)
+		}
+	}
+
+	return moves, nil
+}
+
+// Reader reads EPD records line by line, skipping empty lines.
+type Reader struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+func NewReader(r io.Reader) *Reader {
+	return &Reader{scanner: bufio.NewScanner(r)}
+}
+
+// Read returns the next record, or io.EOF if there is none.
+func (r *Reader) Read() (*Record, error) {
+	for r.scanner.Scan() {
+		r.line++
+		text := strings.TrimSpace(r.scanner.Text())
+		if text == "" {
+			continue
+		}
+		record, err := Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", r.line, err)
+		}
+		return record, nil
+	}
+	if err := r.scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return nil, io.EOF
+}
+
+// Write writes the records, one per line.
+func Write(w io.Writer, records []*Record) error {
+	for _, record := range records {
+		if _, err := fmt.Fprintln(w, record.String()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
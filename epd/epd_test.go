@@ -0,0 +1,139 @@
+package epd
+
+import (
+	"chessBot/board"
+	"chessBot/fen"
+	"io"
+	"strings"
+	"testing"
+)
+
+const wac = `2rr3k/pp3pp1/1nnqbN1p/3pN3/2pP4/2P3Q1/PPB4P/R4RK1 w - - bm Qg6; id "WAC.001";
+8/7p/5k2/5p2/p1p2P2/Pr1pPK2/1P1R3P/8 b - - bm Rxb2; id "WAC.002";
+
+5rk1/1ppb3p/p1pb4/6q1/3P1p1r/2P1R2P/PP1BQ1P1/5RKN w - - bm Rg3; id "WAC.003";
+`
+
+func TestParse(t *testing.T) {
+	record, err := Parse(`2rr3k/pp3pp1/1nnqbN1p/3pN3/2pP4/2P3Q1/PPB4P/R4RK1 w - - bm Qg6; id "WAC.001"; acd 12; c0 "mates; soon";`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if moves := record.Moves(BEST_MOVE); len(moves) != 1 || moves[0].String() != "g3g6" {
+		t.Error("expected bm g3g6, but got", moves)
+	}
+	if id, _ := record.Text(ID); id != "WAC.001" {
+		t.Error("expected id WAC.001, but got", id)
+	}
+	if depth, ok := record.Int(ANALYSIS_DEPTH); !ok || depth != 12 {
+		t.Error("expected acd 12, but got", depth)
+	}
+	if comment, _ := record.Text(COMMENT); comment != "mates; soon" {
+		t.Error("expected the comment to keep its semicolon, but got", comment)
+	}
+	if record.Board.Side != board.WHITE || record.Board.TurnNumber != 1 {
+		t.Error("expected white to move in the first move")
+	}
+}
+
+func TestParseMoveOperands(t *testing.T) {
+	record, err := Parse("r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - am O-O Qxh3; pv Qxh3 Bxe2 Nxe2; hmvc 3; fmvn 20;")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var avoid []string
+	for _, move := range record.Moves(AVOID_MOVE) {
+		avoid = append(avoid, move.String())
+	}
+	if strings.Join(avoid, " ") != "e1g1 f3h3" {
+		t.Error("expected am e1g1 f3h3, but got", avoid)
+	}
+
+	var pv []string
+	for _, move := range record.Moves(PREDICTED_VARIATION) {
+		pv = append(pv, move.String())
+	}
+	if strings.Join(pv, " ") != "f3h3 a6e2 c3e2" {
+		t.Error("expected the pv to be played as a line, but got", pv)
+	}
+	if record.Board.HalfTurns != 3 || record.Board.TurnNumber != 20 {
+		t.Error("expected the clocks from hmvc and fmvn, but got", record.Board.HalfTurns, record.Board.TurnNumber)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, line := range []string{
+		"8/8/8/8/8/8/8/8 w -",
+		"4k3/8/8/8/8/8/8/4K3 w - - bm Ke3;",
+		"4k3/8/8/8/8/8/8/4K3 w - - bm Kd2",
+		`4k3/8/8/8/8/8/8/4K3 w - - id "unterminated;`,
+		"4k3/8/8/8/8/8/8/4K3 w - - ;",
+	} {
+		if _, err := Parse(line); err == nil {
+			t.Errorf("expected an error for %q", line)
+		}
+	}
+}
+
+func TestString(t *testing.T) {
+	for _, line := range []string{
+		`2rr3k/pp3pp1/1nnqbN1p/3pN3/2pP4/2P3Q1/PPB4P/R4RK1 w - - bm Qg6; id "WAC.001";`,
+		`r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - acd 4; am O-O Qxh3; c0 "two words"; pv Qxh3 Bxe2 Nxe2;`,
+		"4k3/8/8/8/8/8/8/4K3 w - -",
+	} {
+		record, err := Parse(line)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if record.String() != line {
+			t.Errorf("expected %s, but got %s", line, record.String())
+		}
+	}
+}
+
+func TestAnalysisRecord(t *testing.T) {
+	b, _ := fen.FenToBoard("6k1/5ppp/8/8/8/8/5PPP/R5K1 w - - 0 1")
+	record := NewRecord(b)
+	if err := record.SetMoves(BEST_MOVE, board.MoveFromString("a1a8")); err != nil {
+		t.Fatal(err)
+	}
+	if err := record.SetMoves(AVOID_MOVE, board.MoveFromString("a1b2")); err == nil {
+		t.Error("expected an illegal move to be rejected")
+	}
+	record.SetInt(ANALYSIS_DEPTH, 3)
+	record.SetInt(CENTIPAWN_EVALUATION, 32766)
+	record.SetText(ID, "back rank")
+
+	expected := `6k1/5ppp/8/8/8/8/5PPP/R5K1 w - - acd 3; bm Ra8#; ce 32766; id "back rank";`
+	if record.String() != expected {
+		t.Errorf("expected %s, but got %s", expected, record.String())
+	}
+}
+
+func TestReader(t *testing.T) {
+	reader := NewReader(strings.NewReader(wac))
+	var ids []string
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		id, _ := record.Text(ID)
+		ids = append(ids, id)
+	}
+
+	if strings.Join(ids, " ") != "WAC.001 WAC.002 WAC.003" {
+		t.Error("expected all three records, but got", ids)
+	}
+
+	reader = NewReader(strings.NewReader("4k3/8/8/8/8/8/8/4K3 w - -\n\n4k3/8/8/8/8/8/8/4K3 w - - bm Ke3;\n"))
+	reader.Read()
+	if _, err := reader.Read(); err == nil || !strings.HasPrefix(err.Error(), "line 3:") {
+		t.Error("expected an error in line 3, but got", err)
+	}
+}
@@ -2,175 +2,402 @@ package fen
 
 import (
 	"chessBot/board"
-	"errors"
+	"fmt"
 	"strconv"
 	"strings"
+	"unicode/utf8"
 )
 
 const STARTPOSSTRING = "startpos"
 const STARTPOSFEN = "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1"
 
-func FenToBoard(fenString string) (*board.Board, error) {
-	if fenString == STARTPOSSTRING {
-		fenString = STARTPOSFEN
-	}
+// Field names a part of a FEN string.
+type Field int
 
-	newBoard := board.NewBoard()
+const (
+	PIECES Field = iota
+	SIDE
+	CASTLING
+	EN_PASSANT
+	HALF_TURNS
+	TURN_NUMBER
+)
 
-	parts := strings.Split(fenString, " ")
-	piecesPart := parts[0]
+var fieldNames = []string{"pieces", "side to move", "castling", "en passant", "halfmove clock", "fullmove number"}
 
-	err := addPieces(newBoard, piecesPart)
-	if err != nil {
-		return nil, err
-	}
+func (f Field) String() string {
+	return fieldNames[f]
+}
 
-	turnPart := parts[1]
-	err = addTurn(newBoard, turnPart)
-	if err != nil {
-		return nil, err
+// Error describes why a FEN string was rejected.
+type Error struct {
+	Field Field
+	// Index is the byte offset of Char in the FEN string, or of the start of the field if the field
+	// as a whole is wrong.
+	Index int
+	// Char is the offending character, 0 if there is none.
+	Char   rune
+	Reason string
+}
+
+func (e *Error) Error() string {
+	if e.Char == 0 {
+		return fmt.Sprintf("invalid fen: %s at %d: %s", e.Field, e.Index, e.Reason)
 	}
 
-	castlingPart := parts[2]
-	err = addCastling(newBoard, castlingPart)
-	if err != nil {
-		return nil, err
+	return fmt.Sprintf("invalid fen: %s at %d (%q): %s", e.Field, e.Index, e.Char, e.Reason)
+}
+
+// field is a part of a FEN string together with its offset in the string.
+type field struct {
+	text   string
+	offset int
+}
+
+// error points at the character starting at the byte index of the field, which may take more than
+// one byte.
+func (f field) error(kind Field, index int, reason string) *Error {
+	if index < 0 || index >= len(f.text) {
+		return &Error{Field: kind, Index: f.offset, Reason: reason}
 	}
+	char, _ := utf8.DecodeRuneInString(f.text[index:])
 
-	enPassetPart := parts[3]
-	err = addEnPasset(newBoard, enPassetPart)
-	if err != nil {
-		return nil, err
+	return &Error{Field: kind, Index: f.offset + index, Char: char, Reason: reason}
+}
+
+// FenToBoard reads a FEN string. The halfmove clock and the fullmove number may be left out, they
+// default to 0 and 1. The position has to be a legal one, see Error for what is wrong otherwise.
+func FenToBoard(fenString string) (*board.Board, error) {
+	if fenString == STARTPOSSTRING {
+		fenString = STARTPOSFEN
 	}
 
-	halfTurnsPart := parts[4]
-	err = addHalfTurns(newBoard, halfTurnsPart)
-	if err != nil {
-		return nil, err
+	fields := splitFields(fenString)
+	if len(fields) < 4 {
+		return nil, &Error{Field: Field(len(fields)), Index: len(fenString), Reason: "missing field"}
+	}
+	if len(fields) > 6 {
+		return nil, &Error{Field: TURN_NUMBER, Index: fields[6].offset, Reason: "unexpected text after the last field"}
+	}
+	for len(fields) < 6 {
+		defaults := []string{"0", "1"}
+		fields = append(fields, field{text: defaults[len(fields)-4], offset: len(fenString)})
 	}
 
-	turnNumberPart := parts[5]
-	err = addTurnNumber(newBoard, turnNumberPart)
-	if err != nil {
-		return nil, err
+	newBoard := board.NewBoard()
+	for _, add := range []func(*board.Board, field) error{
+		addPieces,
+		addTurn,
+		addCastling,
+		addEnPassant,
+		addHalfTurns,
+		addTurnNumber,
+	} {
+		if err := add(newBoard, fields[0]); err != nil {
+			return nil, err
+		}
+		fields = fields[1:]
 	}
+	newBoard.RefreshHash()
 
 	return newBoard, nil
 }
 
-func addHalfTurns(newBoard *board.Board, part string) error {
-	t, err := strconv.Atoi(part)
-	if err != nil {
-		return err
+func splitFields(fenString string) []field {
+	var fields []field
+	start := -1
+	for i := 0; i <= len(fenString); i++ {
+		if i == len(fenString) || fenString[i] == ' ' || fenString[i] == '\t' {
+			if start >= 0 {
+				fields = append(fields, field{text: fenString[start:i], offset: start})
+				start = -1
+			}
+			continue
+		}
+		if start < 0 {
+			start = i
+		}
+	}
+
+	return fields
+}
+
+func addHalfTurns(newBoard *board.Board, f field) error {
+	t, err := strconv.Atoi(f.text)
+	if err != nil || t < 0 {
+		return f.error(HALF_TURNS, -1, "expected a number of at least 0")
 	}
 	newBoard.HalfTurns = t
 
 	return nil
 }
 
-func addTurnNumber(newBoard *board.Board, part string) error {
-	t, err := strconv.Atoi(part)
-	if err != nil {
-		return err
+func addTurnNumber(newBoard *board.Board, f field) error {
+	t, err := strconv.Atoi(f.text)
+	if err != nil || t < 1 {
+		return f.error(TURN_NUMBER, -1, "expected a number of at least 1")
 	}
 	newBoard.TurnNumber = t
 
 	return nil
 }
 
-func addEnPasset(newBoard *board.Board, enPassetPart string) error {
-	if enPassetPart == "-" {
+func addEnPassant(newBoard *board.Board, f field) error {
+	if f.text == "-" {
 		return nil
 	}
-	pos := board.PosFromString(enPassetPart)
+	pos, err := board.ParsePosition(f.text)
+	if err != nil {
+		return f.error(EN_PASSANT, 0, "expected a square or -")
+	}
+
+	// the square was skipped by a pawn of the side not to move with a double push
+	rank, pawnRank, direction := 6, 5, -1
+	if newBoard.Side == board.BLACK {
+		rank, pawnRank, direction = 3, 4, 1
+	}
+	if pos.Rank != rank {
+		return f.error(EN_PASSANT, 1, fmt.Sprintf("expected a square on rank %d", rank))
+	}
+	pawn := newBoard.PieceAt(board.Position{File: pos.File, Rank: pawnRank})
+	if pawn == nil || pawn.Kind != board.PAWN || pawn.Color == newBoard.Side ||
+		newBoard.PieceAt(pos) != nil || newBoard.PieceAt(board.Position{File: pos.File, Rank: pos.Rank - direction}) != nil {
+		return f.error(EN_PASSANT, 0, "no pawn can have just moved across this square")
+	}
 	newBoard.EnPassant = &pos
 
 	return nil
 }
 
-func addCastling(newBoard *board.Board, castlingPart string) error {
-	if castlingPart == "-" {
+func addCastling(newBoard *board.Board, f field) error {
+	if f.text == "-" {
 		return nil
 	}
 
-	for _, c := range castlingPart {
+	for i, c := range f.text {
+		var castling board.Castling
 		switch c {
 		case 'K':
-			newBoard.Castling = append(newBoard.Castling, board.WHITE_KINGSIDE)
+			castling = board.WHITE_KINGSIDE
 		case 'Q':
-			newBoard.Castling = append(newBoard.Castling, board.WHITE_QUEENSIDE)
+			castling = board.WHITE_QUEENSIDE
 		case 'k':
-			newBoard.Castling = append(newBoard.Castling, board.BLACK_KINGSIDE)
+			castling = board.BLACK_KINGSIDE
 		case 'q':
-			newBoard.Castling = append(newBoard.Castling, board.BLACK_QUEENSIDE)
+			castling = board.BLACK_QUEENSIDE
 		default:
-			return errors.New("Invalid castling char: " + string(c))
+			return f.error(CASTLING, i, "unknown castling right")
 		}
+		if newBoard.IsCastlingPossible(castling) {
+			return f.error(CASTLING, i, "duplicate castling right")
+		}
+		move := board.CastlingMoves[castling]
+		if !isPieceAt(newBoard, move.KingFrom, board.KING, move.Side) || !isPieceAt(newBoard, move.RookFrom, board.ROOK, move.Side) {
+			return f.error(CASTLING, i, "king or rook have left their squares")
+		}
+		newBoard.Castling = append(newBoard.Castling, castling)
 	}
 
 	return nil
 }
 
-func addTurn(newBoard *board.Board, turnPart string) error {
-	switch turnPart {
+func isPieceAt(b *board.Board, pos board.Position, kind board.ChessPieceKind, color board.Color) bool {
+	piece := b.PieceAt(pos)
+
+	return piece != nil && piece.Kind == kind && piece.Color == color
+}
+
+func addTurn(newBoard *board.Board, f field) error {
+	switch f.text {
 	case "w":
 		newBoard.Side = board.WHITE
 	case "b":
 		newBoard.Side = board.BLACK
 	default:
-		return errors.New("invalid turn part")
+		return f.error(SIDE, 0, "expected w or b")
+	}
+
+	// the kings were counted with the pieces, so both are on the board
+	bb := board.NewBitboards(newBoard)
+	if bb.IsSquareAttacked(bb.King(newBoard.Side^1), newBoard.Side) {
+		return f.error(SIDE, 0, "the side not to move is in check")
 	}
 
 	return nil
 }
 
-func addPieces(newBoard *board.Board, piecesPart string) error{
-	ranks := strings.Split(piecesPart, "/")
-
-	for i, row := range ranks {
-		rank := 8 - i
-		var file int32
-		for _, c := range row {
-			if file > 7 {
-				break
+func addPieces(newBoard *board.Board, f field) error {
+	rank, file := 8, 0
+	kings := map[board.Color]int{}
+	for i, c := range f.text {
+		if c == '/' {
+			if file != 8 {
+				return f.error(PIECES, i, fmt.Sprintf("rank %d has %d squares instead of 8", rank, file))
 			}
-			if c > '0' && c < '9' {
-				toAdd, err := strconv.Atoi(string(c))
-				if err != nil {
-					return err
-				}
-				file += int32(toAdd)
-				continue
+			if rank == 1 {
+				return f.error(PIECES, i, "more than 8 ranks")
 			}
-
-			var kind board.ChessPieceKind
-			isLower := string(c) == strings.ToLower(string(c))
-			var color board.Color
-			if isLower {
-				color = board.BLACK
-			} else {
-				color = board.WHITE
+			rank--
+			file = 0
+			continue
+		}
+		if c >= '1' && c <= '8' {
+			file += int(c - '0')
+			if file > 8 {
+				return f.error(PIECES, i, fmt.Sprintf("rank %d has more than 8 squares", rank))
 			}
+			continue
+		}
 
-			switch strings.ToLower(string(c)) {
-			case "r":
-				kind = board.ROOK
-			case "n":
-				kind = board.KNIGHT
-			case "b":
-				kind = board.BISHOP
-			case "q":
-				kind = board.QUEEN
-			case "k":
-				kind = board.KING
-			case "p":
-				kind = board.PAWN
-			}
-			newBoard.SetPieceAt(board.Position{File: board.File(file), Rank: rank}, board.NewPiece(kind, color))
-			file++
+		kind, color, ok := pieceFromFen(c)
+		if !ok {
+			return f.error(PIECES, i, "unknown piece")
 		}
+		if file >= 8 {
+			return f.error(PIECES, i, fmt.Sprintf("rank %d has more than 8 squares", rank))
+		}
+		if kind == board.PAWN && (rank == 1 || rank == 8) {
+			return f.error(PIECES, i, "pawn on a back rank")
+		}
+		if kind == board.KING {
+			kings[color]++
+		}
+		newBoard.SetPieceAt(board.Position{File: board.File(file), Rank: rank}, board.NewPiece(kind, color))
+		file++
+	}
+	if rank != 1 || file != 8 {
+		return f.error(PIECES, len(f.text), fmt.Sprintf("expected 8 ranks of 8 squares, the pieces end in rank %d after %d squares", rank, file))
+	}
+	if kings[board.WHITE] != 1 || kings[board.BLACK] != 1 {
+		return f.error(PIECES, -1, "expected exactly one king of each color")
 	}
 
 	return nil
 }
 
+func pieceFromFen(c rune) (board.ChessPieceKind, board.Color, bool) {
+	color := board.WHITE
+	if c >= 'a' && c <= 'z' {
+		color = board.BLACK
+		c -= 'a' - 'A'
+	}
+
+	switch c {
+	case 'P':
+		return board.PAWN, color, true
+	case 'N':
+		return board.KNIGHT, color, true
+	case 'B':
+		return board.BISHOP, color, true
+	case 'R':
+		return board.ROOK, color, true
+	case 'Q':
+		return board.QUEEN, color, true
+	case 'K':
+		return board.KING, color, true
+	}
+
+	return board.PAWN, color, false
+}
+
+// BoardToFen writes all six fields of the FEN string describing the board.
+func BoardToFen(b *board.Board) string {
+	parts := []string{
+		piecesToFen(b),
+		turnToFen(b),
+		castlingToFen(b),
+		enPassantToFen(b),
+		strconv.Itoa(b.HalfTurns),
+		strconv.Itoa(b.TurnNumber),
+	}
+
+	return strings.Join(parts, " ")
+}
+
+func piecesToFen(b *board.Board) string {
+	var ranks []string
+	for rank := 8; rank > 0; rank-- {
+		row := ""
+		empty := 0
+		for _, file := range board.AllFiles {
+			piece := b.PieceAt(board.Position{File: file, Rank: rank})
+			if piece == nil {
+				empty++
+				continue
+			}
+			if empty > 0 {
+				row += strconv.Itoa(empty)
+				empty = 0
+			}
+			row += pieceToFen(piece)
+		}
+		if empty > 0 {
+			row += strconv.Itoa(empty)
+		}
+		ranks = append(ranks, row)
+	}
+
+	return strings.Join(ranks, "/")
+}
+
+func pieceToFen(piece *board.Piece) string {
+	var letter string
+	switch piece.Kind {
+	case board.PAWN:
+		letter = "p"
+	case board.KNIGHT:
+		letter = "n"
+	case board.BISHOP:
+		letter = "b"
+	case board.ROOK:
+		letter = "r"
+	case board.QUEEN:
+		letter = "q"
+	case board.KING:
+		letter = "k"
+	}
+	if piece.Color == board.WHITE {
+		letter = strings.ToUpper(letter)
+	}
+
+	return letter
+}
+
+func turnToFen(b *board.Board) string {
+	if b.Side == board.BLACK {
+		return "b"
+	}
+
+	return "w"
+}
+
+func castlingToFen(b *board.Board) string {
+	castling := ""
+	// the rights are always written in this order, no matter how they are stored
+	for _, c := range []struct {
+		castling board.Castling
+		letter   string
+	}{
+		{board.WHITE_KINGSIDE, "K"},
+		{board.WHITE_QUEENSIDE, "Q"},
+		{board.BLACK_KINGSIDE, "k"},
+		{board.BLACK_QUEENSIDE, "q"},
+	} {
+		if b.IsCastlingPossible(c.castling) {
+			castling += c.letter
+		}
+	}
+	if castling == "" {
+		return "-"
+	}
+
+	return castling
+}
+
+func enPassantToFen(b *board.Board) string {
+	if b.EnPassant == nil {
+		return "-"
+	}
+
+	return b.EnPassant.String()
+}
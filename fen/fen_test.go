@@ -2,6 +2,7 @@ package fen
 
 import (
 	"chessBot/board"
+	"strings"
 	"testing"
 )
 
@@ -27,15 +28,15 @@ func TestCastling(t *testing.T) {
 
 	for _, testCase := range []struct{Fen string; Expected []board.Castling}{
 		{
-			Fen:      "8/8/8/8/8/8/8/8 w KQkq - 0 1",
+			Fen:      "r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1",
 			Expected: []board.Castling{board.WHITE_KINGSIDE, board.WHITE_QUEENSIDE, board.BLACK_KINGSIDE, board.BLACK_QUEENSIDE},
 		},
 		{
-			Fen:      "8/8/8/8/8/8/8/8 w Kq - 0 1",
+			Fen:      "r3k2r/8/8/8/8/8/8/R3K2R w Kq - 0 1",
 			Expected: []board.Castling{board.WHITE_KINGSIDE, board.BLACK_QUEENSIDE},
 		},
 		{
-			Fen:      "8/8/8/8/8/8/8/8 w - - 0 1",
+			Fen:      "r3k2r/8/8/8/8/8/8/R3K2R w - - 0 1",
 			Expected: []board.Castling{},
 		},
 	} {
@@ -57,7 +58,7 @@ func TestCastling(t *testing.T) {
 }
 
 func TestHalfTurns(t *testing.T) {
-	createdBoard, err := FenToBoard("8/8/8/8/8/8/8/8 w KQkq - 10 1")
+	createdBoard, err := FenToBoard("4k3/8/8/8/8/8/8/4K3 w - - 10 1")
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -68,7 +69,7 @@ func TestHalfTurns(t *testing.T) {
 }
 
 func TestTurnNumber(t *testing.T) {
-	createdBoard, err := FenToBoard("8/8/8/8/8/8/8/8 w KQkq - 1 10")
+	createdBoard, err := FenToBoard("4k3/8/8/8/8/8/8/4K3 w - - 1 10")
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -82,11 +83,11 @@ func TestEnPasset(t *testing.T) {
 
 	for _, testCase := range []struct{Fen string; Expected *board.Position}{
 		{
-			Fen:      "8/8/8/8/8/8/8/8 w KQkq - 0 1",
+			Fen:      "4k3/8/8/8/5P2/8/8/4K3 b - - 0 1",
 			Expected: nil,
 		},
 		{
-			Fen:      "8/8/8/8/8/8/8/8 w KQkq f3 0 1",
+			Fen:      "4k3/8/8/8/5P2/8/8/4K3 b - f3 0 1",
 			Expected: &board.Position{
 				File: board.F,
 				Rank: 3,
@@ -109,7 +110,7 @@ func TestEnPasset(t *testing.T) {
 }
 
 func TestTurn(t *testing.T) {
-	createdBoard, err := FenToBoard("8/8/8/8/8/8/8/8 w KQkq - 0 1")
+	createdBoard, err := FenToBoard("4k3/8/8/8/8/8/8/4K3 w - - 0 1")
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -118,7 +119,7 @@ func TestTurn(t *testing.T) {
 		t.Error("Expected white's turn")
 	}
 
-	createdBoard, err = FenToBoard("8/8/8/8/8/8/8/8 b KQkq - 0 1")
+	createdBoard, err = FenToBoard("4k3/8/8/8/8/8/8/4K3 b - - 0 1")
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -128,6 +129,122 @@ func TestTurn(t *testing.T) {
 	}
 }
 
+// fenCorpus holds positions from real games, test suites and endgame studies
+var fenCorpus = []string{
+	STARTPOSFEN,
+	"rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1",
+	"rnbqkbnr/pp1ppppp/8/2p5/4P3/8/PPPP1PPP/RNBQKBNR w KQkq c6 0 2",
+	"rnbqkbnr/pp1ppppp/8/2p5/4P3/5N2/PPPP1PPP/RNBQKB1R b KQkq - 1 2",
+	"r1bqkbnr/pppp1ppp/2n5/1B2p3/4P3/5N2/PPPP1PPP/RNBQK2R b KQkq - 3 3",
+	"r1bqk2r/2ppbppp/p1n2n2/1p2p3/4P3/1B3N2/PPPP1PPP/RNBQR1K1 b kq - 1 7",
+	"r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1",
+	"8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1",
+	"r3k2r/Pppp1ppp/1b3nbN/nP6/BBP1P3/q4N2/Pp1P2PP/R2Q1RK1 w kq - 0 1",
+	"rnbq1k1r/pp1Pbppp/2p5/8/2B5/8/PPP1NnPP/RNBQK2R w KQ - 1 8",
+	"r4rk1/1pp1qppp/p1np1n2/2b1p1B1/2B1P1b1/P1NP1N2/1PP1QPPP/R4RK1 w - - 0 10",
+	"2r3k1/p4p2/3Rp2p/1p2P1pK/8/1P4P1/P3Q2P/1q6 b - - 0 1",
+	"1K1k4/1P6/8/8/8/8/r7/2R5 w - - 0 1",
+	"8/8/4k3/8/8/4K3/4P3/8 w - - 0 1",
+	"8/5k2/8/8/8/8/1K6/8 b - - 99 120",
+	"r1b1k2r/ppppnppp/2n2q2/2b5/3NP3/2P1B3/PP3PPP/RN1QKB1R w KQkq - 0 7",
+}
+
+func TestBoardToFen(t *testing.T) {
+	for _, fenString := range fenCorpus {
+		createdBoard, err := FenToBoard(fenString)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		actual := BoardToFen(createdBoard)
+		if actual != fenString {
+			t.Errorf("expected %s, but got %s", fenString, actual)
+		}
+	}
+}
+
+func TestBoardToFenAfterMoves(t *testing.T) {
+	createdBoard, _ := FenToBoard(STARTPOSFEN)
+	for _, move := range []string{"e2e4", "c7c5", "g1f3"} {
+		createdBoard.MakeMove(board.MoveFromString(move))
+	}
+
+	expected := "rnbqkbnr/pp1ppppp/8/2p5/4P3/5N2/PPPP1PPP/RNBQKB1R b KQkq - 1 2"
+	if actual := BoardToFen(createdBoard); actual != expected {
+		t.Errorf("expected %s, but got %s", expected, actual)
+	}
+}
+
+func TestBoardToFenCastlingOrder(t *testing.T) {
+	createdBoard := board.NewBoard()
+	createdBoard.Castling = []board.Castling{board.BLACK_QUEENSIDE, board.WHITE_KINGSIDE}
+	createdBoard.TurnNumber = 1
+
+	expected := "8/8/8/8/8/8/8/8 w Kq - 0 1"
+	if actual := BoardToFen(createdBoard); actual != expected {
+		t.Errorf("expected %s, but got %s", expected, actual)
+	}
+}
+
+func TestTruncatedFen(t *testing.T) {
+	createdBoard, err := FenToBoard("rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if createdBoard.HalfTurns != 0 || createdBoard.TurnNumber != 1 {
+		t.Error("expected the clocks to default to 0 and 1, but got", createdBoard.HalfTurns, createdBoard.TurnNumber)
+	}
+	if createdBoard.EnPassant == nil || createdBoard.EnPassant.String() != "e3" {
+		t.Error("expected en passant on e3, but got", createdBoard.EnPassant)
+	}
+}
+
+func TestInvalidFen(t *testing.T) {
+	for _, testCase := range []struct {
+		desc  string
+		fen   string
+		field Field
+		char  rune
+	}{
+		{"missing fields", "4k3/8/8/8/8/8/8/4K3 w", CASTLING, 0},
+		{"too many fields", "4k3/8/8/8/8/8/8/4K3 w - - 0 1 x", TURN_NUMBER, 0},
+		{"long rank", "4k4/8/8/8/8/8/8/4K3 w - - 0 1", PIECES, '4'},
+		{"short rank", "4k2/8/8/8/8/8/8/4K3 w - - 0 1", PIECES, '/'},
+		{"too many ranks", "4k3/8/8/8/8/8/8/4K3/8 w - - 0 1", PIECES, '/'},
+		{"too few ranks", "4k3/8/8/8/8/8/4K3 w - - 0 1", PIECES, 0},
+		{"unknown piece", "4k3/8/8/8/8/8/8/4K2X w - - 0 1", PIECES, 'X'},
+		{"unknown piece of more than one byte", "4k3/8/8/8/8/8/8/3♔K3 w - - 0 1", PIECES, '♔'},
+		{"missing king", "8/8/8/8/8/8/8/4K3 w - - 0 1", PIECES, 0},
+		{"two kings", "4k3/8/8/8/8/8/8/3KK3 w - - 0 1", PIECES, 0},
+		{"pawn on back rank", "4k2P/8/8/8/8/8/8/4K3 w - - 0 1", PIECES, 'P'},
+		{"unknown side", "4k3/8/8/8/8/8/8/4K3 x - - 0 1", SIDE, 'x'},
+		{"side not to move in check", "4k3/8/8/8/8/8/8/4R1K1 w - - 0 1", SIDE, 'w'},
+		{"unknown castling", "r3k2r/8/8/8/8/8/8/R3K2R w KX - 0 1", CASTLING, 'X'},
+		{"duplicate castling", "r3k2r/8/8/8/8/8/8/R3K2R w KK - 0 1", CASTLING, 'K'},
+		{"castling without rook", "r3k3/8/8/8/8/8/8/R3K2R w KQkq - 0 1", CASTLING, 'k'},
+		{"castling with moved king", "r3k2r/8/8/8/8/8/8/R4K1R w Q - 0 1", CASTLING, 'Q'},
+		{"garbage en passant", "4k3/8/8/8/4P3/8/8/4K3 b - z9 0 1", EN_PASSANT, 'z'},
+		{"en passant on wrong rank", "4k3/8/8/8/4P3/8/8/4K3 b - e4 0 1", EN_PASSANT, '4'},
+		{"en passant without pawn", "4k3/8/8/8/8/8/8/4K3 b - e3 0 1", EN_PASSANT, 'e'},
+		{"negative halfmove clock", "4k3/8/8/8/8/8/8/4K3 w - - -1 1", HALF_TURNS, 0},
+		{"zero fullmove number", "4k3/8/8/8/8/8/8/4K3 w - - 0 0", TURN_NUMBER, 0},
+	} {
+		_, err := FenToBoard(testCase.fen)
+		fenErr, ok := err.(*Error)
+		if !ok {
+			t.Errorf("%s: expected a fen error, but got %v", testCase.desc, err)
+			continue
+		}
+		if fenErr.Field != testCase.field || fenErr.Char != testCase.char {
+			t.Errorf("%s: expected an error in %s at %q, but got %v", testCase.desc, testCase.field, testCase.char, fenErr)
+		}
+		if fenErr.Char != 0 && !strings.HasPrefix(testCase.fen[fenErr.Index:], string(fenErr.Char)) {
+			t.Errorf("%s: expected %q at index %d", testCase.desc, fenErr.Char, fenErr.Index)
+		}
+	}
+}
+
 func assertStartPosition(boardToTest *board.Board, t *testing.T) {
 	for f, p := range map[board.File]*board.Piece{
 		board.A: {Kind: board.ROOK, Color: board.WHITE},
@@ -1,15 +1,290 @@
 package chessBot
 
-import "chessBot/board"
+import (
+	"chessBot/board"
+	"chessBot/engine"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
 
+var (
+	ErrInvalidSAN    = errors.New("invalid SAN")
+	ErrIllegalMove   = errors.New("illegal move")
+	ErrAmbiguousMove = errors.New("ambiguous move")
+)
+
+// AN is a move in standard algebraic notation. File and Rank are the square the piece moves to,
+// FromFile and FromRank tell pieces of the same kind apart that could move there.
 type AN struct {
-	File string
-	Rank int
-	Piece board.ChessPieceKind
-	IsMove bool
-	IsCapture bool
-	IsPromotion bool
-	PromotesTo board.ChessPieceKind
-	IsKingsideCastling bool
+	File                string
+	Rank                int
+	FromFile            string
+	FromRank            int
+	Piece               board.ChessPieceKind
+	IsMove              bool
+	IsCapture           bool
+	IsPromotion         bool
+	PromotesTo          board.ChessPieceKind
+	IsKingsideCastling  bool
 	IsQueensideCastling bool
+	IsCheck             bool
+	IsMate              bool
+}
+
+var pieceLetters = [6]string{"", "N", "B", "R", "Q", "K"}
+
+func (an AN) String() string {
+	var san strings.Builder
+
+	switch {
+	case an.IsKingsideCastling:
+		san.WriteString("O-O")
+	case an.IsQueensideCastling:
+		san.WriteString("O-O-O")
+	default:
+		san.WriteString(pieceLetters[an.Piece])
+		san.WriteString(an.FromFile)
+		if an.FromRank > 0 {
+			san.WriteString(strconv.Itoa(an.FromRank))
+		}
+		if an.IsCapture {
+			san.WriteString("x")
+		}
+		san.WriteString(an.File + strconv.Itoa(an.Rank))
+		if an.IsPromotion {
+			san.WriteString("=" + pieceLetters[an.PromotesTo])
+		}
+	}
+
+	switch {
+	case an.IsMate:
+		san.WriteString("#")
+	case an.IsCheck:
+		san.WriteString("+")
+	}
+
+	return san.String()
+}
+
+// SAN writes a legal move in standard algebraic notation, like Nbd7, exd6, O-O-O or e8=Q+.
+func SAN(b *board.Board, move board.Move) (string, error) {
+	an, err := MoveToAN(b, move)
+	if err != nil {
+		return "", err
+	}
+
+	return an.String(), nil
+}
+
+// LegalMove returns the legal move on the board with the origin, target and promotion of the move.
+// Its flags, like whether it captures or castles, are set by the board, as moves parsed from long
+// algebraic notation have none.
+func LegalMove(b *board.Board, move board.Move) (board.Move, error) {
+	for _, legalMove := range engine.LegalMoves(b) {
+		if legalMove.SameAs(move) {
+			return legalMove, nil
+		}
+	}
+
+	return board.NullMove, fmt.Errorf("%w %s", ErrIllegalMove, move)
+}
+
+// MoveToAN describes a legal move on the board, with as little disambiguation as needed and
+// whether it gives check or mate. Illegal moves have no notation and return ErrIllegalMove.
+func MoveToAN(b *board.Board, move board.Move) (AN, error) {
+	move, err := LegalMove(b, move)
+	if err != nil {
+		return AN{}, err
+	}
+
+	piece := b.PieceAt(move.From)
+	to := move.To.String()
+	an := AN{
+		File:                to[:1],
+		Rank:                move.To.Rank,
+		Piece:               piece.Kind,
+		IsMove:              true,
+		IsCapture:           move.IsCapture,
+		IsPromotion:         move.Promotion != board.PAWN,
+		PromotesTo:          move.Promotion,
+		IsKingsideCastling:  move.IsCastling && move.To.File == board.G,
+		IsQueensideCastling: move.IsCastling && move.To.File == board.C,
+	}
+	if piece.Kind == board.PAWN {
+		if move.IsCapture {
+			an.FromFile = move.From.String()[:1]
+		}
+	} else {
+		an.FromFile, an.FromRank = disambiguation(b, move, piece.Kind)
+	}
+
+	undo := b.MakeMove(move)
+	an.IsCheck = engine.InCheck(b)
+	an.IsMate = an.IsCheck && len(engine.LegalMoves(b)) == 0
+	b.UnmakeMove(undo)
+
+	return an, nil
+}
+
+// disambiguation names as little of the origin of the move as needed to tell it apart from
+// moves of other pieces of the same kind to the same square: the file if possible, then the
+// rank, then both.
+func disambiguation(b *board.Board, move board.Move, kind board.ChessPieceKind) (string, int) {
+	ambiguous, sameFile, sameRank := false, false, false
+	for _, other := range engine.LegalMoves(b) {
+		if other.To != move.To || other.From == move.From || b.PieceAt(other.From).Kind != kind {
+			continue
+		}
+		ambiguous = true
+		sameFile = sameFile || other.From.File == move.From.File
+		sameRank = sameRank || other.From.Rank == move.From.Rank
+	}
+
+	file := move.From.String()[:1]
+	switch {
+	case !ambiguous:
+		return "", 0
+	case !sameFile:
+		return file, 0
+	case !sameRank:
+		return "", move.From.Rank
+	}
+
+	return file, move.From.Rank
+}
+
+// ParseAN reads the notation of a move without looking at a board. Castling may be written with
+// zeros, annotations like ! or ?! are ignored.
+func ParseAN(san string) (AN, error) {
+	text := strings.TrimRight(san, "!?")
+	an := AN{IsMove: true}
+	switch {
+	case strings.HasSuffix(text, "#"):
+		an.IsMate, an.IsCheck = true, true
+		text = text[:len(text)-1]
+	case strings.HasSuffix(text, "+"):
+		an.IsCheck = true
+		text = text[:len(text)-1]
+	}
+
+	switch text {
+	case "O-O", "0-0":
+		an.IsKingsideCastling = true
+		an.Piece = board.KING
+		return an, nil
+	case "O-O-O", "0-0-0":
+		an.IsQueensideCastling = true
+		an.Piece = board.KING
+		return an, nil
+	}
+
+	if text != "" && strings.ContainsRune("NBRQK", rune(text[0])) {
+		an.Piece = pieceFromLetter(text[0])
+		text = text[1:]
+	}
+
+	// the promotion is at the end, with or without an equals sign
+	if n := len(text); n > 0 && strings.ContainsRune("NBRQ", rune(text[n-1])) {
+		if an.Piece != board.PAWN {
+			return AN{}, fmt.Errorf("%w %q: only pawns promote", ErrInvalidSAN, san)
+		}
+		an.IsPromotion = true
+		an.PromotesTo = pieceFromLetter(text[n-1])
+		text = strings.TrimSuffix(text[:n-1], "=")
+	}
+
+	if len(text) < 2 {
+		return AN{}, fmt.Errorf("%w %q: missing the square to move to", ErrInvalidSAN, san)
+	}
+	to, err := board.ParsePosition(text[len(text)-2:])
+	if err != nil {
+		return AN{}, fmt.Errorf("%w %q: %v", ErrInvalidSAN, san, err)
+	}
+	an.File, an.Rank = text[len(text)-2:len(text)-1], to.Rank
+	text = text[:len(text)-2]
+
+	if strings.HasSuffix(text, "x") {
+		an.IsCapture = true
+		text = text[:len(text)-1]
+	}
+	for _, c := range text {
+		switch {
+		case c >= 'a' && c <= 'h' && an.FromFile == "" && an.FromRank == 0:
+			an.FromFile = string(c)
+		case c >= '1' && c <= '8' && an.FromRank == 0:
+			an.FromRank = int(c - '0')
+		default:
+			return AN{}, fmt.Errorf("%w %q: unexpected %q", ErrInvalidSAN, san, c)
+		}
+	}
+	if an.IsPromotion && an.Rank != 1 && an.Rank != 8 {
+		return AN{}, fmt.Errorf("%w %q: promotion before the last rank", ErrInvalidSAN, san)
+	}
+
+	return an, nil
+}
+
+func pieceFromLetter(letter byte) board.ChessPieceKind {
+	for kind, l := range pieceLetters {
+		if l == string(letter) {
+			return board.ChessPieceKind(kind)
+		}
+	}
+
+	return board.PAWN
+}
+
+// ParseSAN returns the legal move on the board the notation stands for. Check and mate suffixes
+// are not verified, a missing x on a capture is accepted.
+func ParseSAN(b *board.Board, san string) (board.Move, error) {
+	an, err := ParseAN(san)
+	if err != nil {
+		return board.NullMove, err
+	}
+
+	var candidates []board.Move
+	for _, move := range engine.LegalMoves(b) {
+		if an.matches(b, move) {
+			candidates = append(candidates, move)
+		}
+	}
+
+	switch len(candidates) {
+	case 0:
+		return board.NullMove, fmt.Errorf("%w %s", ErrIllegalMove, san)
+	case 1:
+		return candidates[0], nil
+	}
+
+	var from []string
+	for _, move := range candidates {
+		from = append(from, move.From.String())
+	}
+
+	return board.NullMove, fmt.Errorf("%w %s: it could be played from %s", ErrAmbiguousMove, san, strings.Join(from, " or "))
+}
+
+func (an AN) matches(b *board.Board, move board.Move) bool {
+	if an.IsKingsideCastling || an.IsQueensideCastling {
+		return move.IsCastling && (move.To.File == board.G) == an.IsKingsideCastling
+	}
+	to := move.To.String()
+	from := move.From.String()
+
+	switch {
+	case move.IsCastling || b.PieceAt(move.From).Kind != an.Piece:
+		return false
+	case to[:1] != an.File || move.To.Rank != an.Rank:
+		return false
+	case an.FromFile != "" && from[:1] != an.FromFile:
+		return false
+	case an.FromRank != 0 && move.From.Rank != an.FromRank:
+		return false
+	case an.IsCapture && !move.IsCapture:
+		return false
+	}
+
+	return move.Promotion == an.PromotesTo
 }
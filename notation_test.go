@@ -0,0 +1,130 @@
+package chessBot
+
+import (
+	"chessBot/board"
+	"chessBot/fen"
+	"errors"
+	"testing"
+)
+
+func TestSAN(t *testing.T) {
+	for _, testCase := range []struct {
+		fen      string
+		move     string
+		expected string
+	}{
+		{fen.STARTPOSFEN, "g1f3", "Nf3"},
+		{fen.STARTPOSFEN, "e2e4", "e4"},
+		{"r1bqkb1r/pppp1ppp/2n2n2/4p3/4P3/2N2N2/PPPP1PPP/R1BQKB1R w KQkq - 4 4", "c3d5", "Nd5"},
+		{"r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1", "e1c1", "O-O-O"},
+		{"r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1", "e1g1", "O-O"},
+		{"4k3/8/8/3pP3/8/8/8/4K3 w - d6 0 1", "e5d6", "exd6"},
+		{"4k3/P7/8/8/8/8/8/4K3 w - - 0 1", "a7a8q", "a8=Q+"},
+		{"1n2k3/P7/8/8/8/8/8/4K3 w - - 0 1", "a7b8n", "axb8=N"},
+		{"r1bqkb1r/pppp1ppp/2n2n2/4p2Q/2B1P3/8/PPPP1PPP/RNB1K1NR w KQkq - 4 4", "h5f7", "Qxf7#"},
+		// knights on b8 and f6 can both go to d7
+		{"rn1qkb1r/ppp1pppp/5n2/8/8/8/PPPPPPPP/RNBQKBNR b KQkq - 0 1", "b8d7", "Nbd7"},
+		// rooks on a1 and a7 share the file
+		{"4k3/R7/8/8/8/8/8/R3K3 w Q - 0 1", "a1a4", "R1a4"},
+		// queens on a1, a3 and c1 all reach b2
+		{"4k3/8/8/8/8/Q7/8/Q1Q1K3 w - - 0 1", "a1b2", "Qa1b2"},
+	} {
+		b, err := fen.FenToBoard(testCase.fen)
+		if err != nil {
+			t.Fatal(err)
+		}
+		move, err := ParseSAN(b, testCase.expected)
+		if err != nil {
+			t.Errorf("%s: %v", testCase.expected, err)
+			continue
+		}
+		if move.String() != testCase.move {
+			t.Errorf("expected %s to be %s, but got %s", testCase.expected, testCase.move, move)
+		}
+		if san, err := SAN(b, move); err != nil || san != testCase.expected {
+			t.Errorf("expected %s to be written %s, but got %s, %v", testCase.move, testCase.expected, san, err)
+		}
+	}
+}
+
+func TestSANOfMovesWithoutFlags(t *testing.T) {
+	for _, testCase := range []struct {
+		fen      string
+		move     string
+		expected string
+	}{
+		{"rnbqkbnr/ppp1pppp/8/3p4/4P3/8/PPPP1PPP/RNBQKBNR w KQkq d6 0 2", "e4d5", "exd5"},
+		{"r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1", "e1g1", "O-O"},
+		{"r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1", "e1c1", "O-O-O"},
+		{"4k3/8/8/3pP3/8/8/8/4K3 w - d6 0 1", "e5d6", "exd6"},
+		{"rnbqkbnr/pppp1ppp/8/4p3/4P3/5N2/PPPP1PPP/RNBQKB1R w KQkq - 0 2", "f3e5", "Nxe5"},
+	} {
+		b, err := fen.FenToBoard(testCase.fen)
+		if err != nil {
+			t.Fatal(err)
+		}
+		move, err := board.ParseMove(testCase.move)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if san, err := SAN(b, move); err != nil || san != testCase.expected {
+			t.Errorf("expected %s to be written %s, but got %s, %v", testCase.move, testCase.expected, san, err)
+		}
+		if parsed, err := ParseSAN(b, testCase.expected); err != nil || !parsed.SameAs(move) {
+			t.Errorf("expected %s to be read back as %s, but got %s, %v", testCase.expected, testCase.move, parsed, err)
+		}
+	}
+}
+
+func TestSANOfIllegalMove(t *testing.T) {
+	b, _ := fen.FenToBoard(fen.STARTPOSFEN)
+	move, _ := board.ParseMove("e2e5")
+	if _, err := LegalMove(b, move); !errors.Is(err, ErrIllegalMove) {
+		t.Errorf("expected an illegal move, but got %v", err)
+	}
+	if _, err := SAN(b, move); !errors.Is(err, ErrIllegalMove) {
+		t.Errorf("expected SAN to reject the illegal move, but got %v", err)
+	}
+}
+
+func TestParseSANErrors(t *testing.T) {
+	for _, testCase := range []struct {
+		fen      string
+		san      string
+		expected error
+	}{
+		{fen.STARTPOSFEN, "e5", ErrIllegalMove},
+		{fen.STARTPOSFEN, "Nf4", ErrIllegalMove},
+		{fen.STARTPOSFEN, "O-O", ErrIllegalMove},
+		{fen.STARTPOSFEN, "Ke9", ErrInvalidSAN},
+		{fen.STARTPOSFEN, "Zf3", ErrInvalidSAN},
+		{fen.STARTPOSFEN, "Nf3=Q", ErrInvalidSAN},
+		{fen.STARTPOSFEN, "e4=Q", ErrInvalidSAN},
+		{fen.STARTPOSFEN, "", ErrInvalidSAN},
+		{"rn1qkb1r/ppp1pppp/5n2/8/8/8/PPPPPPPP/RNBQKBNR b KQkq - 0 1", "Nd7", ErrAmbiguousMove},
+		{"4k3/P7/8/8/8/8/8/4K3 w - - 0 1", "a8", ErrIllegalMove},
+	} {
+		b, _ := fen.FenToBoard(testCase.fen)
+		_, err := ParseSAN(b, testCase.san)
+		if !errors.Is(err, testCase.expected) {
+			t.Errorf("%q: expected %v, but got %v", testCase.san, testCase.expected, err)
+		}
+	}
+}
+
+func TestParseAN(t *testing.T) {
+	an, err := ParseAN("exd8=Q+!?")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := AN{File: "d", Rank: 8, FromFile: "e", Piece: board.PAWN, IsMove: true, IsCapture: true, IsPromotion: true, PromotesTo: board.QUEEN, IsCheck: true}
+	if an != expected {
+		t.Errorf("expected %+v, but got %+v", expected, an)
+	}
+
+	for _, castling := range []string{"0-0", "O-O"} {
+		if an, _ := ParseAN(castling); !an.IsKingsideCastling || an.String() != "O-O" {
+			t.Errorf("expected %s to castle kingside, but got %+v", castling, an)
+		}
+	}
+}
@@ -0,0 +1,224 @@
+package pgn
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	symbolKind tokenKind = iota
+	stringKind
+	nagKind
+	suffixKind
+	commentKind
+	openBracketKind
+	closeBracketKind
+	openParenthesisKind
+	closeParenthesisKind
+	periodKind
+	asteriskKind
+	eofKind
+)
+
+type token struct {
+	kind   tokenKind
+	text   string
+	line   int
+	column int
+}
+
+// lexer splits PGN into tokens, keeping track of where they start.
+type lexer struct {
+	input  *bufio.Reader
+	line   int
+	column int
+	// the column of the last line, to step back over a newline
+	lastColumn int
+	peeked     *token
+}
+
+func newLexer(r io.Reader) *lexer {
+	return &lexer{input: bufio.NewReader(r), line: 1}
+}
+
+func (l *lexer) read() (rune, bool) {
+	r, _, err := l.input.ReadRune()
+	if err != nil {
+		return 0, false
+	}
+	if r == '\n' {
+		l.line++
+		l.lastColumn = l.column
+		l.column = 0
+	} else {
+		l.column++
+	}
+
+	return r, true
+}
+
+func (l *lexer) unread(r rune) {
+	l.input.UnreadRune()
+	if r == '\n' {
+		l.line--
+		l.column = l.lastColumn
+	} else {
+		l.column--
+	}
+}
+
+func (l *lexer) errorf(line int, column int, message string) *Error {
+	return &Error{Line: line, Column: column, Message: message}
+}
+
+// unreadToken makes the next call to next return t again.
+func (l *lexer) unreadToken(t token) {
+	l.peeked = &t
+}
+
+func (l *lexer) next() (token, error) {
+	if l.peeked != nil {
+		t := *l.peeked
+		l.peeked = nil
+		return t, nil
+	}
+
+	for {
+		r, ok := l.read()
+		if !ok {
+			return token{kind: eofKind, line: l.line, column: l.column + 1}, nil
+		}
+		t := token{line: l.line, column: l.column}
+
+		switch {
+		case unicode.IsSpace(r):
+			continue
+		case r == '%' && t.column == 1:
+			// escaped lines are for other programs
+			l.skipLine()
+			continue
+		case r == ';':
+			t.kind = commentKind
+			t.text = strings.TrimSpace(l.skipLine())
+			return t, nil
+		case r == '{':
+			text, ok := l.readUntil('}')
+			if !ok {
+				return t, l.errorf(t.line, t.column, "comment is not closed")
+			}
+			t.kind = commentKind
+			t.text = strings.Join(strings.Fields(text), " ")
+			return t, nil
+		case r == '"':
+			text, err := l.readString()
+			if err != nil {
+				return t, l.errorf(t.line, t.column, err.Error())
+			}
+			t.kind = stringKind
+			t.text = text
+			return t, nil
+		case r == '$':
+			t.kind = nagKind
+			t.text = l.readWhile(unicode.IsDigit)
+			if t.text == "" {
+				return t, l.errorf(t.line, t.column, "expected a number after $")
+			}
+			return t, nil
+		case r == '!' || r == '?':
+			t.kind = suffixKind
+			t.text = string(r) + l.readWhile(func(r rune) bool { return r == '!' || r == '?' })
+			return t, nil
+		case isSymbolStart(r):
+			t.kind = symbolKind
+			t.text = string(r) + l.readWhile(isSymbolContinuation)
+			return t, nil
+		}
+
+		if kind, ok := delimiters[r]; ok {
+			t.kind = kind
+			t.text = string(r)
+			return t, nil
+		}
+
+		return t, l.errorf(t.line, t.column, "unexpected character "+string(r))
+	}
+}
+
+var delimiters = map[rune]tokenKind{
+	'[': openBracketKind,
+	']': closeBracketKind,
+	'(': openParenthesisKind,
+	')': closeParenthesisKind,
+	'.': periodKind,
+	'*': asteriskKind,
+}
+
+func isSymbolStart(r rune) bool {
+	return r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r))
+}
+
+func isSymbolContinuation(r rune) bool {
+	return isSymbolStart(r) || strings.ContainsRune("_+#=:-/", r)
+}
+
+func (l *lexer) readWhile(accept func(rune) bool) string {
+	var text strings.Builder
+	for {
+		r, ok := l.read()
+		if !ok {
+			return text.String()
+		}
+		if !accept(r) {
+			l.unread(r)
+			return text.String()
+		}
+		text.WriteRune(r)
+	}
+}
+
+func (l *lexer) readUntil(end rune) (string, bool) {
+	var text strings.Builder
+	for {
+		r, ok := l.read()
+		if !ok {
+			return text.String(), false
+		}
+		if r == end {
+			return text.String(), true
+		}
+		text.WriteRune(r)
+	}
+}
+
+func (l *lexer) skipLine() string {
+	text, _ := l.readUntil('\n')
+
+	return text
+}
+
+// readString reads the rest of a string, where \" and \\ stand for a quote and a backslash.
+func (l *lexer) readString() (string, error) {
+	var text strings.Builder
+	for {
+		r, ok := l.read()
+		switch {
+		case !ok || r == '\n':
+			return "", errors.New("string is not closed")
+		case r == '"':
+			return text.String(), nil
+		case r == '\\':
+			escaped, ok := l.read()
+			if !ok {
+				return "", errors.New("string is not closed")
+			}
+			text.WriteRune(escaped)
+		default:
+			text.WriteRune(r)
+		}
+	}
+}
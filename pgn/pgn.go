@@ -0,0 +1,66 @@
+package pgn
+
+import (
+	"chessBot/board"
+	"fmt"
+	"time"
+)
+
+// SevenTagRoster are the tags every game has, in the order they are exported.
+var SevenTagRoster = []string{"Event", "Site", "Date", "Round", "White", "Black", "Result"}
+
+// Game is a game with its moves and variations.
+type Game struct {
+	Tags map[string]string
+	// Board is the position before the first move, the start position unless the FEN tag sets up
+	// another one.
+	Board *board.Board
+	// Moves is the first move of the main line, nil if the game has no moves.
+	Moves  *Node
+	Result string
+}
+
+// MainLine returns the moves of the game without the variations.
+func (g *Game) MainLine() []board.Move {
+	var moves []board.Move
+	for node := g.Moves; node != nil; node = node.Next {
+		moves = append(moves, node.Move)
+	}
+
+	return moves
+}
+
+// Node is a move of a line and the alternatives to it.
+type Node struct {
+	Move board.Move
+	SAN  string
+	// CommentsBefore are the comments in front of the move, Comments the ones following it.
+	CommentsBefore []string
+	Comments       []string
+	// NAGs are the numeric annotation glyphs of the move, !? is stored as 5.
+	NAGs []int
+
+	// the [%clk] and [%eval] annotations of the comments, the evaluation is from white's point of view
+	Clock    time.Duration
+	HasClock bool
+	Eval     int
+	MateIn   int
+	HasEval  bool
+	// Analysis is set for moves played by an engine.
+	Analysis *Analysis
+
+	// Variations are lines played instead of this move.
+	Variations []*Node
+	Next       *Node
+}
+
+// Error is a problem found at a line and column of a PGN file.
+type Error struct {
+	Line    int
+	Column  int
+	Message string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("line %d, column %d: %s", e.Line, e.Column, e.Message)
+}
@@ -0,0 +1,289 @@
+package pgn
+
+import (
+	"chessBot"
+	"chessBot/board"
+	"chessBot/fen"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// suffixes are the move annotations that can be written without a NAG
+var suffixes = map[string]int{"!": 1, "?": 2, "!!": 3, "??": 4, "!?": 5, "?!": 6}
+
+var results = map[string]bool{"1-0": true, "0-1": true, "1/2-1/2": true, "*": true}
+
+// Reader reads the games of a PGN file one after another.
+type Reader struct {
+	lexer *lexer
+	// inMovetext is set once the tags of the game being read are done
+	inMovetext bool
+}
+
+func NewReader(r io.Reader) *Reader {
+	return &Reader{lexer: newLexer(r)}
+}
+
+// Read returns the next game, or io.EOF after the last one. All moves are replayed on the board. If
+// a game cannot be read, an *Error is returned and the next call continues with the following game.
+func (r *Reader) Read() (*Game, error) {
+	r.inMovetext = false
+	game, err := r.readGame()
+	if err != nil && err != io.EOF {
+		r.skipGame()
+	}
+
+	return game, err
+}
+
+func (r *Reader) readGame() (*Game, error) {
+	game := &Game{Tags: map[string]string{}}
+
+	t, err := r.lexer.next()
+	if err != nil {
+		return nil, err
+	}
+	if t.kind == eofKind {
+		return nil, io.EOF
+	}
+	fenTag := t
+	for t.kind == openBracketKind {
+		name, value, err := r.readTag()
+		if err != nil {
+			return nil, err
+		}
+		if name == "FEN" {
+			fenTag = t
+		}
+		game.Tags[name] = value
+
+		if t, err = r.lexer.next(); err != nil {
+			return nil, err
+		}
+	}
+	r.lexer.unreadToken(t)
+	r.inMovetext = true
+
+	game.Board, err = fen.FenToBoard(fen.STARTPOSFEN)
+	if fenString, ok := game.Tags["FEN"]; ok {
+		game.Board, err = fen.FenToBoard(fenString)
+	}
+	if err != nil {
+		return nil, r.lexer.errorf(fenTag.line, fenTag.column, err.Error())
+	}
+
+	game.Moves, game.Result, err = r.readLine(game.Board.Copy(), 0)
+	if err != nil {
+		return nil, err
+	}
+	if game.Result == "" {
+		game.Result = "*"
+		if result, ok := game.Tags["Result"]; ok {
+			game.Result = result
+		}
+	}
+
+	return game, nil
+}
+
+// readTag reads a tag after its opening bracket.
+func (r *Reader) readTag() (string, string, error) {
+	var tokens [3]token
+	for i, kind := range []tokenKind{symbolKind, stringKind, closeBracketKind} {
+		t, err := r.lexer.next()
+		if err != nil {
+			return "", "", err
+		}
+		if t.kind != kind {
+			return "", "", r.lexer.errorf(t.line, t.column, "expected a tag like [Event \"name\"]")
+		}
+		tokens[i] = t
+	}
+
+	return tokens[0].text, tokens[1].text, nil
+}
+
+// readLine reads moves played from the board until the end of the variation or the game. It
+// returns the first move of the line and the result, if the line ends with one.
+func (r *Reader) readLine(b *board.Board, depth int) (*Node, string, error) {
+	var first, last *Node
+	var commentsBefore []string
+	// the position before the last move, where its variations start
+	var previous *board.Board
+
+	for {
+		t, err := r.lexer.next()
+		if err != nil {
+			return nil, "", err
+		}
+
+		switch t.kind {
+		case periodKind:
+		case symbolKind, asteriskKind:
+			if results[t.text] {
+				if depth > 0 {
+					return nil, "", r.lexer.errorf(t.line, t.column, "result inside a variation")
+				}
+				return first, t.text, nil
+			}
+			if _, err := strconv.Atoi(t.text); err == nil {
+				// a move number
+				continue
+			}
+
+			move, err := chessBot.ParseSAN(b, t.text)
+			if err != nil {
+				return nil, "", r.lexer.errorf(t.line, t.column, err.Error())
+			}
+			san, err := chessBot.SAN(b, move)
+			if err != nil {
+				return nil, "", r.lexer.errorf(t.line, t.column, err.Error())
+			}
+			node := &Node{Move: move, SAN: san, CommentsBefore: commentsBefore}
+			commentsBefore = nil
+			previous = b.Copy()
+			b.MakeMove(move)
+			if last == nil {
+				first = node
+			} else {
+				last.Next = node
+			}
+			last = node
+		case nagKind, suffixKind:
+			nag, ok := suffixes[t.text]
+			if t.kind == nagKind {
+				nag, err = strconv.Atoi(t.text)
+				ok = err == nil
+			}
+			if !ok || last == nil {
+				return nil, "", r.lexer.errorf(t.line, t.column, "unexpected annotation "+t.text)
+			}
+			last.NAGs = append(last.NAGs, nag)
+		case commentKind:
+			if last == nil || len(commentsBefore) > 0 {
+				commentsBefore = append(commentsBefore, t.text)
+				continue
+			}
+			if err := last.addComment(t.text); err != nil {
+				return nil, "", r.lexer.errorf(t.line, t.column, err.Error())
+			}
+		case openParenthesisKind:
+			if last == nil {
+				return nil, "", r.lexer.errorf(t.line, t.column, "variation without a move to replace")
+			}
+			variation, _, err := r.readLine(previous.Copy(), depth+1)
+			if err != nil {
+				return nil, "", err
+			}
+			if variation != nil {
+				last.Variations = append(last.Variations, variation)
+			}
+		case closeParenthesisKind:
+			if depth == 0 {
+				return nil, "", r.lexer.errorf(t.line, t.column, "closing a variation that was not opened")
+			}
+			return first, "", nil
+		case openBracketKind, eofKind:
+			// the tags of the next game are left to it
+			r.lexer.unreadToken(t)
+			if depth > 0 {
+				return nil, "", r.lexer.errorf(t.line, t.column, "variation is not closed")
+			}
+			// the game ends without a result
+			return first, "", nil
+		default:
+			return nil, "", r.lexer.errorf(t.line, t.column, "unexpected "+t.text)
+		}
+	}
+}
+
+// skipGame throws away what is left of a game, up to its result or the tags of the next game.
+func (r *Reader) skipGame() {
+	inTag := false
+	for {
+		t, err := r.lexer.next()
+		if err != nil {
+			continue
+		}
+
+		switch {
+		case t.kind == eofKind:
+			r.lexer.unreadToken(t)
+			return
+		case t.kind == openBracketKind && r.inMovetext && t.column == 1:
+			r.lexer.unreadToken(t)
+			return
+		case t.kind == openBracketKind:
+			inTag = true
+		case t.kind == closeBracketKind:
+			inTag = false
+		case inTag:
+		case results[t.text]:
+			return
+		default:
+			r.inMovetext = true
+		}
+	}
+}
+
+var annotation = regexp.MustCompile(`\[%(\w+)\s+([^\]]*)\]`)
+
+// addComment adds the comment following the move, taking out the clock and eval annotations.
+func (n *Node) addComment(text string) error {
+	for _, match := range annotation.FindAllStringSubmatch(text, -1) {
+		value := strings.TrimSpace(match[2])
+		switch match[1] {
+		case "clk":
+			clock, err := parseClock(value)
+			if err != nil {
+				return err
+			}
+			n.Clock, n.HasClock = clock, true
+		case "eval":
+			if strings.HasPrefix(value, "#") {
+				mate, err := strconv.Atoi(value[1:])
+				if err != nil {
+					return fmt.Errorf("invalid mate %s", value)
+				}
+				n.MateIn, n.HasEval = mate, true
+			} else {
+				pawns, err := strconv.ParseFloat(value, 64)
+				if err != nil {
+					return fmt.Errorf("invalid evaluation %s", value)
+				}
+				n.Eval, n.HasEval = int(math.Round(pawns*100)), true
+			}
+		default:
+			// annotations we do not know stay in the comment
+			continue
+		}
+		text = strings.Replace(text, match[0], "", 1)
+	}
+
+	if text = strings.Join(strings.Fields(text), " "); text != "" {
+		n.Comments = append(n.Comments, text)
+	}
+
+	return nil
+}
+
+// parseClock reads a clock like 1:02:03 or 0:00:04.5.
+func parseClock(value string) (time.Duration, error) {
+	parts := strings.Split(value, ":")
+	if len(parts) != 3 {
+		return 0, fmt.Errorf("invalid clock %s", value)
+	}
+	hours, err1 := strconv.Atoi(parts[0])
+	minutes, err2 := strconv.Atoi(parts[1])
+	seconds, err3 := strconv.ParseFloat(parts[2], 64)
+	if err1 != nil || err2 != nil || err3 != nil {
+		return 0, fmt.Errorf("invalid clock %s", value)
+	}
+
+	return time.Duration(hours)*time.Hour + time.Duration(minutes)*time.Minute + time.Duration(seconds*float64(time.Second)), nil
+}
@@ -0,0 +1,221 @@
+package pgn
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+const evansGambit = `[Event "Casual Game"]
+[Site "Berlin GER"]
+[Date "1852.??.??"]
+[Round "?"]
+[White "Adolf Anderssen"]
+[Black "Jean Dufresne"]
+[Result "1-0"]
+[Annotator "Someone \"quoted\""]
+
+{The Evergreen Game} 1. e4 {[%clk 0:05:00] [%eval 0.3] the king's pawn} e5 $1
+2. Nf3 Nc6 (2... d6 3. d4 (3. Bc4) 3... Nf6) 3. Bc4!? Bc5?! ; the Evans gambit follows
+% an escaped line is ignored
+4. b4 {[%eval #-3]} 1-0
+`
+
+func TestReadGame(t *testing.T) {
+	game, err := NewReader(strings.NewReader(evansGambit)).Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, expected := range map[string]string{
+		"Event":     "Casual Game",
+		"White":     "Adolf Anderssen",
+		"Result":    "1-0",
+		"Annotator": `Someone "quoted"`,
+	} {
+		if game.Tags[name] != expected {
+			t.Errorf("expected tag %s to be %s, but got %s", name, expected, game.Tags[name])
+		}
+	}
+	if game.Result != "1-0" {
+		t.Error("expected 1-0, but got", game.Result)
+	}
+
+	var mainLine []string
+	for _, move := range game.MainLine() {
+		mainLine = append(mainLine, move.String())
+	}
+	if strings.Join(mainLine, " ") != "e2e4 e7e5 g1f3 b8c6 f1c4 f8c5 b2b4" {
+		t.Error("unexpected main line", mainLine)
+	}
+
+	first := game.Moves
+	if len(first.CommentsBefore) != 1 || first.CommentsBefore[0] != "The Evergreen Game" {
+		t.Error("expected a comment before the first move, but got", first.CommentsBefore)
+	}
+	if len(first.Comments) != 1 || first.Comments[0] != "the king's pawn" {
+		t.Error("expected the annotations to be taken out of the comment, but got", first.Comments)
+	}
+	if !first.HasClock || first.Clock != 5*time.Minute || !first.HasEval || first.Eval != 30 {
+		t.Errorf("expected clock and eval of the first move, but got %+v", first)
+	}
+	if len(first.Next.NAGs) != 1 || first.Next.NAGs[0] != 1 {
+		t.Error("expected $1 on e5, but got", first.Next.NAGs)
+	}
+
+	knight := first.Next.Next.Next
+	if knight.SAN != "Nc6" || len(knight.Variations) != 1 {
+		t.Fatalf("expected a variation to Nc6, but got %+v", knight)
+	}
+	variation := knight.Variations[0]
+	if variation.SAN != "d6" || variation.Next.SAN != "d4" || variation.Next.Next.SAN != "Nf6" {
+		t.Error("unexpected variation", variation.SAN, variation.Next.SAN, variation.Next.Next.SAN)
+	}
+	if len(variation.Next.Variations) != 1 || variation.Next.Variations[0].SAN != "Bc4" {
+		t.Error("expected a nested variation to d4")
+	}
+
+	bishop := knight.Next
+	if len(bishop.NAGs) != 1 || bishop.NAGs[0] != 5 || len(bishop.Next.NAGs) != 1 || bishop.Next.NAGs[0] != 6 {
+		t.Error("expected !? and ?! as NAGs", bishop.NAGs, bishop.Next.NAGs)
+	}
+	if len(bishop.Next.Comments) != 1 || bishop.Next.Comments[0] != "the Evans gambit follows" {
+		t.Error("expected the line comment, but got", bishop.Next.Comments)
+	}
+	if last := bishop.Next.Next; !last.HasEval || last.MateIn != -3 {
+		t.Errorf("expected a mate for black in the eval, but got %+v", last)
+	}
+}
+
+func TestReadSeveralGames(t *testing.T) {
+	input := evansGambit + `
+[Event "Endgame"]
+[FEN "4k3/8/8/8/8/8/4P3/4K3 w - - 0 1"]
+[SetUp "1"]
+
+1. e4 Kd7 2. e5
+
+[Event "No moves"]
+[Result "1/2-1/2"]
+
+1/2-1/2
+`
+	reader := NewReader(strings.NewReader(input))
+	var events []string
+	for {
+		game, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		events = append(events, game.Tags["Event"])
+
+		if game.Tags["Event"] == "Endgame" {
+			if len(game.MainLine()) != 3 || game.Result != "*" {
+				t.Errorf("expected three moves without result, but got %v %s", game.MainLine(), game.Result)
+			}
+			if game.Board.PieceAt(game.MainLine()[0].From) == nil {
+				t.Error("expected the game to start from the FEN tag")
+			}
+		}
+	}
+
+	if strings.Join(events, ", ") != "Casual Game, Endgame, No moves" {
+		t.Error("expected all games, but got", events)
+	}
+}
+
+func TestReadErrors(t *testing.T) {
+	for _, testCase := range []struct {
+		desc   string
+		pgn    string
+		line   int
+		column int
+	}{
+		{"illegal move", "[Event \"a\"]\n\n1. e4 e5 2. Ke3 Nc6 1-0\n", 3, 13},
+		{"unknown move", "1. e4 Zz9 *", 1, 7},
+		{"result in variation", "1. e4 (1. d4 1-0) e5 *", 1, 14},
+		{"unclosed variation", "1. e4 (1. d4 d5", 1, 16},
+		{"unopened variation", "1. e4 ) *", 1, 7},
+		{"broken tag", "[Event]\n1. e4 *", 1, 7},
+		{"invalid fen", "[Event \"a\"]\n[FEN \"8/8/8/8/8/8/8/8 w - - 0 1\"]\n1. e4 *", 2, 1},
+		{"annotation before move", "$1 e4 *", 1, 1},
+		{"unclosed comment", "1. e4 {never closed", 1, 7},
+		{"invalid clock", "1. e4 {[%clk soon]} *", 1, 7},
+	} {
+		_, err := NewReader(strings.NewReader(testCase.pgn)).Read()
+		pgnErr, ok := err.(*Error)
+		if !ok {
+			t.Errorf("%s: expected an error, but got %v", testCase.desc, err)
+			continue
+		}
+		if pgnErr.Line != testCase.line || pgnErr.Column != testCase.column {
+			t.Errorf("%s: expected an error at %d:%d, but got %v", testCase.desc, testCase.line, testCase.column, pgnErr)
+		}
+	}
+}
+
+func TestReadContinuesAfterError(t *testing.T) {
+	input := `[Event "first"]
+
+1. e4 e5 2. Ke3 Nc6 1-0
+
+[Event "second"]
+[Result "0-1"]
+
+1. f3 e5 2. g4 Qh4# 0-1
+
+[Event "third"]
+
+1. d4 (1. e4 1-0) d5 *
+
+[Event "fourth"]
+
+1. c4 *
+`
+	reader := NewReader(strings.NewReader(input))
+	var events []string
+	errors := 0
+	for {
+		game, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			errors++
+			continue
+		}
+		events = append(events, game.Tags["Event"])
+	}
+
+	if errors != 2 || strings.Join(events, ", ") != "second, fourth" {
+		t.Errorf("expected two errors and the second and fourth game, but got %d and %v", errors, events)
+	}
+}
+
+func TestReadKeepsTagsAfterOpenVariation(t *testing.T) {
+	input := `[Event "a"]
+[Site "a"]
+
+1. e4 (1. d4 d5
+
+[Event "b"]
+[Site "b"]
+
+1. c4 *
+`
+	reader := NewReader(strings.NewReader(input))
+	if _, err := reader.Read(); err == nil {
+		t.Fatal("expected an error for the variation that is not closed")
+	}
+	game, err := reader.Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if game.Tags["Event"] != "b" || game.Tags["Site"] != "b" {
+		t.Errorf("expected all tags of the second game, but got %v", game.Tags)
+	}
+}
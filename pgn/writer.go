@@ -0,0 +1,304 @@
+package pgn
+
+import (
+	"chessBot"
+	"chessBot/board"
+	"chessBot/fen"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const lineLength = 80
+
+// rosterDefaults are written for tags of the seven tag roster the game does not have.
+var rosterDefaults = map[string]string{"Date": "????.??.??"}
+
+// NewGame starts a game without moves from the board.
+func NewGame(b *board.Board) *Game {
+	return &Game{Tags: map[string]string{}, Board: b.Copy(), Result: "*"}
+}
+
+// Append plays the move at the end of the main line and returns its node, for example to add the
+// analysis of the engine that played it. The move has to be legal, it may come without flags, like
+// the best move of an engine. An illegal move leaves the game as it was.
+func (g *Game) Append(move board.Move) (*Node, error) {
+	b := g.Board.Copy()
+	last := g.Moves
+	for _, m := range g.MainLine() {
+		b.MakeMove(m)
+	}
+
+	move, err := chessBot.LegalMove(b, move)
+	if err != nil {
+		return nil, err
+	}
+	san, err := chessBot.SAN(b, move)
+	if err != nil {
+		return nil, err
+	}
+	node := &Node{Move: move, SAN: san}
+	if last == nil {
+		g.Moves = node
+		return node, nil
+	}
+	for last.Next != nil {
+		last = last.Next
+	}
+	last.Next = node
+
+	return node, nil
+}
+
+// Analysis is what an engine thought of the move it played. It is written as a comment like
+// {+0.35/12 1.2s}.
+type Analysis struct {
+	// Score is in centipawns from the point of view of the side playing the move, MateIn is set
+	// instead for mates and negative if the side gets mated.
+	Score  int
+	MateIn int
+	Depth  int
+	Time   time.Duration
+}
+
+func (a Analysis) String() string {
+	score := fmt.Sprintf("%+.2f", float64(a.Score)/100)
+	switch {
+	case a.MateIn > 0:
+		score = fmt.Sprintf("+M%d", a.MateIn)
+	case a.MateIn < 0:
+		score = fmt.Sprintf("-M%d", -a.MateIn)
+	}
+
+	return fmt.Sprintf("%s/%d %ss", score, a.Depth, strconv.FormatFloat(a.Time.Seconds(), 'f', -1, 64))
+}
+
+// Writer writes games in the PGN export format, separated by empty lines.
+type Writer struct {
+	w       io.Writer
+	written bool
+}
+
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: w}
+}
+
+func (w *Writer) Write(game *Game) error {
+	text := game.String()
+	if w.written {
+		text = "\n" + text
+	}
+	w.written = true
+	_, err := io.WriteString(w.w, text)
+
+	return err
+}
+
+// String returns the game in the PGN export format: the seven tag roster first, the other tags
+// sorted by name and the movetext wrapped at 80 columns.
+func (g *Game) String() string {
+	var out strings.Builder
+	for _, tag := range g.tagOrder() {
+		value := g.Tags[tag]
+		if _, ok := g.Tags[tag]; !ok {
+			value = "?"
+			if d, ok := rosterDefaults[tag]; ok {
+				value = d
+			}
+		}
+		if tag == "Result" {
+			value = g.result()
+		}
+		if tag == "FEN" && g.Tags["FEN"] == "" {
+			value = fen.BoardToFen(g.Board)
+		}
+		if tag == "SetUp" && g.isSetUp() {
+			value = "1"
+		}
+		value = strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value)
+		fmt.Fprintf(&out, "[%s \"%s\"]\n", tag, value)
+	}
+	out.WriteString("\n")
+
+	words := (&movetext{}).line(g.Board.Copy(), g.Moves)
+	words = append(words, g.result())
+	out.WriteString(wrap(words))
+
+	return out.String()
+}
+
+func (g *Game) result() string {
+	if g.Result == "" {
+		return "*"
+	}
+
+	return g.Result
+}
+
+func (g *Game) tagOrder() []string {
+	tags := append([]string{}, SevenTagRoster...)
+	if g.isSetUp() {
+		tags = addTag(tags, "SetUp")
+		tags = addTag(tags, "FEN")
+	}
+
+	var others []string
+	for tag := range g.Tags {
+		if !contains(tags, tag) {
+			others = append(others, tag)
+		}
+	}
+	sort.Strings(others)
+
+	return append(tags, others...)
+}
+
+// isSetUp tells whether the game needs the SetUp and FEN tags, because it does not start from the
+// start position or was read with a FEN tag.
+func (g *Game) isSetUp() bool {
+	if _, ok := g.Tags["FEN"]; ok {
+		return true
+	}
+
+	return fen.BoardToFen(g.Board) != fen.STARTPOSFEN
+}
+
+func addTag(tags []string, tag string) []string {
+	if contains(tags, tag) {
+		return tags
+	}
+
+	return append(tags, tag)
+}
+
+func contains(tags []string, tag string) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// movetext collects the words of the moves, comments and variations.
+type movetext struct {
+	words []string
+	// needsNumber is set if the next move needs its number, even if black plays it
+	needsNumber bool
+}
+
+func (m *movetext) line(b *board.Board, node *Node) []string {
+	m.needsNumber = true
+	for ; node != nil; node = node.Next {
+		// the line ends at an illegal move, the moves after it cannot be written from the board
+		san, err := chessBot.SAN(b, node.Move)
+		if err != nil {
+			break
+		}
+		m.comments(node.CommentsBefore)
+
+		if b.Side == board.WHITE {
+			m.words = append(m.words, strconv.Itoa(b.TurnNumber)+".")
+		} else if m.needsNumber {
+			m.words = append(m.words, strconv.Itoa(b.TurnNumber)+"...")
+		}
+		m.needsNumber = false
+		m.words = append(m.words, san)
+		for _, nag := range node.NAGs {
+			m.words = append(m.words, "$"+strconv.Itoa(nag))
+		}
+
+		if node.Analysis != nil {
+			m.comments([]string{node.Analysis.String()})
+		}
+		// the clock and eval annotations go into the first comment
+		var annotations []string
+		if node.HasClock {
+			annotations = append(annotations, "[%clk "+formatClock(node.Clock)+"]")
+		}
+		if node.HasEval {
+			annotations = append(annotations, "[%eval "+formatEval(node)+"]")
+		}
+		comments := node.Comments
+		if len(annotations) > 0 {
+			if len(comments) > 0 {
+				annotations = append(annotations, comments[0])
+				comments = comments[1:]
+			}
+			comments = append([]string{strings.Join(annotations, " ")}, comments...)
+		}
+		m.comments(comments)
+
+		for _, variation := range node.Variations {
+			// the parentheses are not separated from the first and the last word
+			words := (&movetext{}).line(b.Copy(), variation)
+			if len(words) == 0 {
+				continue
+			}
+			words[0] = "(" + words[0]
+			words[len(words)-1] += ")"
+			m.words = append(m.words, words...)
+			m.needsNumber = true
+		}
+
+		b.MakeMove(node.Move)
+	}
+
+	return m.words
+}
+
+func (m *movetext) comments(comments []string) {
+	for _, comment := range comments {
+		// a closing brace cannot be escaped, it would end the comment early
+		words := strings.Fields(strings.Replace(comment, "}", "", -1))
+		if len(words) == 0 {
+			continue
+		}
+		words[0] = "{" + words[0]
+		words[len(words)-1] += "}"
+		m.words = append(m.words, words...)
+		m.needsNumber = true
+	}
+}
+
+func formatClock(clock time.Duration) string {
+	seconds := strconv.FormatFloat((clock % time.Minute).Seconds(), 'f', -1, 64)
+	if clock%time.Minute < 10*time.Second {
+		seconds = "0" + seconds
+	}
+
+	return fmt.Sprintf("%d:%02d:%s", clock/time.Hour, clock/time.Minute%60, seconds)
+}
+
+func formatEval(node *Node) string {
+	if node.MateIn != 0 {
+		return "#" + strconv.Itoa(node.MateIn)
+	}
+
+	return fmt.Sprintf("%.2f", float64(node.Eval)/100)
+}
+
+// wrap joins the words to lines of at most 80 columns.
+func wrap(words []string) string {
+	var out strings.Builder
+	column := 0
+	for _, word := range words {
+		if column > 0 && column+1+len(word) > lineLength {
+			out.WriteString("\n")
+			column = 0
+		}
+		if column > 0 {
+			out.WriteString(" ")
+			column++
+		}
+		out.WriteString(word)
+		column += len(word)
+	}
+	out.WriteString("\n")
+
+	return out.String()
+}
@@ -0,0 +1,205 @@
+package pgn
+
+import (
+	"bytes"
+	"chessBot"
+	"chessBot/board"
+	"chessBot/fen"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteReadGame(t *testing.T) {
+	game, err := NewReader(strings.NewReader(evansGambit)).Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `[Event "Casual Game"]
+[Site "Berlin GER"]
+[Date "1852.??.??"]
+[Round "?"]
+[White "Adolf Anderssen"]
+[Black "Jean Dufresne"]
+[Result "1-0"]
+[Annotator "Someone \"quoted\""]
+
+{The Evergreen Game} 1. e4 {[%clk 0:05:00] [%eval 0.30] the king's pawn} 1... e5
+$1 2. Nf3 Nc6 (2... d6 3. d4 (3. Bc4) 3... Nf6) 3. Bc4 $5 Bc5 $6 {the Evans
+gambit follows} 4. b4 {[%eval #-3]} 1-0
+`
+	if game.String() != expected {
+		t.Errorf("expected\n%s\nbut got\n%s", expected, game.String())
+	}
+
+	reread, err := NewReader(strings.NewReader(game.String())).Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reread.String() != expected {
+		t.Errorf("expected the written game to read back the same, but got\n%s", reread.String())
+	}
+}
+
+func TestWriteEngineGame(t *testing.T) {
+	b, _ := fen.FenToBoard("r1bqkbnr/pppp1ppp/2n5/4p2Q/2B1P3/8/PPPP1PPP/RNB1K1NR b KQkq - 3 3")
+	game := NewGame(b)
+	game.Tags["White"] = "OutstandingMove"
+	game.Tags["TimeControl"] = "60+1"
+	game.Tags["Black"] = "Opponent"
+	game.Tags["ECO"] = "C20"
+
+	for i, move := range []string{"Nf6", "Qxf7#"} {
+		parsed, err := chessBot.ParseSAN(b, move)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b.MakeMove(parsed)
+		node, err := game.Append(parsed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		node.Analysis = &Analysis{Score: -35 + i*35, Depth: 12, Time: 1200 * time.Millisecond}
+	}
+	game.Moves.Next.Analysis.MateIn = 1
+	game.Result = "1-0"
+
+	expected := `[Event "?"]
+[Site "?"]
+[Date "????.??.??"]
+[Round "?"]
+[White "OutstandingMove"]
+[Black "Opponent"]
+[Result "1-0"]
+[SetUp "1"]
+[FEN "r1bqkbnr/pppp1ppp/2n5/4p2Q/2B1P3/8/PPPP1PPP/RNB1K1NR b KQkq - 3 3"]
+[ECO "C20"]
+[TimeControl "60+1"]
+
+3... Nf6 {-0.35/12 1.2s} 4. Qxf7# {+M1/12 1.2s} 1-0
+`
+	if game.String() != expected {
+		t.Errorf("expected\n%s\nbut got\n%s", expected, game.String())
+	}
+}
+
+func TestWriteStartPositionFen(t *testing.T) {
+	game, err := NewReader(strings.NewReader(`[Event "?"]
+[Annotator "Someone"]
+[FEN "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1"]
+
+1. e4 *
+`)).Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `[Event "?"]
+[Site "?"]
+[Date "????.??.??"]
+[Round "?"]
+[White "?"]
+[Black "?"]
+[Result "*"]
+[SetUp "1"]
+[FEN "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1"]
+[Annotator "Someone"]
+
+1. e4 *
+`
+	if game.String() != expected {
+		t.Errorf("expected\n%s\nbut got\n%s", expected, game.String())
+	}
+}
+
+func TestWriteMovetextEdgeCases(t *testing.T) {
+	b, _ := fen.FenToBoard(fen.STARTPOSFEN)
+	game := NewGame(b)
+	node, err := game.Append(board.MoveFromString("e2e4"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	node.Comments = []string{"a {brace} inside"}
+	node.Variations = []*Node{nil}
+
+	expected := "1. e4 {a {brace inside} *\n"
+	if !strings.HasSuffix(game.String(), expected) {
+		t.Errorf("expected the movetext %q, but got\n%s", expected, game.String())
+	}
+	reread, err := NewReader(strings.NewReader(game.String())).Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if comments := reread.Moves.Comments; len(comments) != 1 || comments[0] != "a {brace inside" {
+		t.Errorf("expected the comment to read back without the brace, but got %q", comments)
+	}
+}
+
+func TestWriteWrapsLines(t *testing.T) {
+	b, _ := fen.FenToBoard(fen.STARTPOSFEN)
+	game := NewGame(b)
+	for i := 0; i < 40; i++ {
+		for _, move := range []string{"Nf3", "Nf6", "Ng1", "Ng8"} {
+			parsed, _ := chessBot.ParseSAN(b, move)
+			b.MakeMove(parsed)
+			node, _ := game.Append(parsed)
+			node.Comments = []string{"a comment that is long enough to need wrapping"}
+		}
+	}
+
+	var out bytes.Buffer
+	writer := NewWriter(&out)
+	writer.Write(game)
+	writer.Write(game)
+
+	for _, line := range strings.Split(out.String(), "\n") {
+		if len(line) > 80 {
+			t.Errorf("expected at most 80 columns, but got %d in %q", len(line), line)
+		}
+	}
+
+	reader := NewReader(&out)
+	for i := 0; i < 2; i++ {
+		reread, err := reader.Read()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(reread.MainLine()) != 160 {
+			t.Error("expected 160 moves, but got", len(reread.MainLine()))
+		}
+	}
+}
+
+func TestAppendMovesWithoutFlags(t *testing.T) {
+	b, _ := fen.FenToBoard("r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1")
+	game := NewGame(b)
+	for _, move := range []string{"e1g1", "a8a1", "f1a1"} {
+		parsed, err := board.ParseMove(move)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := game.Append(parsed); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := game.Append(board.MoveFromString("a1a8")); err == nil {
+		t.Error("expected an illegal move to be rejected")
+	}
+
+	var sans []string
+	for node := game.Moves; node != nil; node = node.Next {
+		sans = append(sans, node.SAN)
+	}
+	if strings.Join(sans, " ") != "O-O Rxa1 Rxa1" {
+		t.Errorf("expected O-O Rxa1 Rxa1, but got %v", sans)
+	}
+
+	reread, err := NewReader(strings.NewReader(game.String())).Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reread.String() != game.String() {
+		t.Errorf("expected the game to read back the same, but got\n%s", reread.String())
+	}
+}
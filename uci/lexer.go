@@ -1,8 +1,6 @@
 package uci
 
 import (
-	"fmt"
-	"sort"
 	"strings"
 )
 
@@ -41,8 +39,54 @@ const (
 	stop        keyword = "stop"
 	ponderhit   keyword = "ponderhit"
 	quit        keyword = "quit"
+
+	// the keywords of the messages from the engine to the GUI
+	id             keyword = "id"
+	author         keyword = "author"
+	uciok          keyword = "uciok"
+	readyok        keyword = "readyok"
+	bestmove       keyword = "bestmove"
+	copyprotection keyword = "copyprotection"
+	registration   keyword = "registration"
+	checking       keyword = "checking"
+	ok             keyword = "ok"
+	error_         keyword = "error"
+	option         keyword = "option"
+	type_          keyword = "type"
+	default_       keyword = "default"
+	min            keyword = "min"
+	max            keyword = "max"
+	var_           keyword = "var"
+	info           keyword = "info"
+	seldepth       keyword = "seldepth"
+	time_          keyword = "time"
+	pv             keyword = "pv"
+	multipv        keyword = "multipv"
+	score          keyword = "score"
+	cp             keyword = "cp"
+	lowerbound     keyword = "lowerbound"
+	upperbound     keyword = "upperbound"
+	currmove       keyword = "currmove"
+	currmovenumber keyword = "currmovenumber"
+	hashfull       keyword = "hashfull"
+	nps            keyword = "nps"
+	tbhits         keyword = "tbhits"
+	cpuload        keyword = "cpuload"
+	string_        keyword = "string"
+	refutation     keyword = "refutation"
+	currline       keyword = "currline"
 )
 
+var keywords = map[keyword]bool{}
+
+func init() {
+	for _, kw := range []keyword{value, binc, btime, code, debug, depth, fen, go_, infinite, isready, later, mate, moves, movestogo, movetime, name, nodes, off, on, ponder, ponderhit, position, quit, register, searchmoves, setoption, startpos, stop, uci, ucinewgame, winc, wtime,
+		id, author, uciok, readyok, bestmove, copyprotection, registration, checking, ok, error_, option, type_, default_, min, max, var_,
+		info, seldepth, time_, pv, multipv, score, cp, lowerbound, upperbound, currmove, currmovenumber, hashfull, nps, tbhits, cpuload, string_, refutation, currline} {
+		keywords[kw] = true
+	}
+}
+
 type symbol byte
 
 const (
@@ -59,172 +103,84 @@ const (
 )
 
 type token struct {
-	kind  tokenKind
+	kind tokenKind
+	// value is the keyword in lower case for keywords, the text otherwise
 	value string
+	// text is the word as it was sent
+	text string
+	// line and column of the first character, both start at 1
+	line   int
+	column int
 }
 
 func (t *token) equals(other *token) bool {
 	return t.kind == other.kind && t.value == other.value
 }
 
-type cursor uint
-
-type lexer func(source string, cur cursor) (*token, cursor, bool)
-
-func Lex(source string) ([]*token, error) {
-	var cur cursor
-
-	lexers := []lexer{symbolLexer, keywordLexer, longAlgebraicNotationLexer, stringLexer}
-	var tokens []*token
-lex:
-	for cur < cursor(len(source)) {
-		cur = eatWhitespace(source, cur)
-		for _, lexer := range lexers {
-			t, newCursor, ok := lexer(source, cur)
-			if ok {
-				tokens = append(tokens, t)
-				cur = newCursor
-				continue lex
-			}
-		}
-
-		return nil, fmt.Errorf("error lexing at position %d", cur)
-	}
-
-	return tokens, nil
-}
-
-func expect(source string, ic cursor, searchString string) bool {
-	return strings.HasPrefix(source[ic:], searchString)
+func (t *token) is(k keyword) bool {
+	return t.kind == keywordKind && t.value == string(k)
 }
 
-func stringLexer(source string, ic cursor) (*token, cursor, bool){
-	cur := ic
-	value := ""
-	for cur < cursor(len(source)) && source[cur] != ' ' && source[cur] != uint8(newLine) {
-		value += string(source[cur])
-		cur++
-	}
-
-	return &token{
-		kind:  stringKind,
-		value: value,
-	}, cur, true
-}
-
-func longAlgebraicNotationLexer(source string, ic cursor) (*token, cursor, bool) {
-	if len(source[ic:]) < 4 {
-		return nil, ic, false
-	}
-
-	if expect(source, ic, "0000") {
-		return &token{
-			kind:  longAlgebraicNotation,
-			value: "0000",
-		}, ic+4, true
-	}
-
-	validFiles := []byte{'a','b','c','d','e','f','g','h'}
-	validPiecesForPromotion := []byte{'r','k','q','n','b'}
-
-	cur := ic
-	value := ""
+// Lex splits the source into words separated by white space and classifies them. Only whole words
+// are keywords, in any case. Each new line is a token of its own.
+func Lex(source string) []*token {
+	var tokens []*token
+	line, lineStart := 1, 0
+	start := -1
 
-	for range []int{0, 1} {
-		current := source[cur]
-		isValidFile := false
-		for _, f := range validFiles {
-			if current == f {
-				isValidFile = true
-				break
+	for i := 0; i <= len(source); i++ {
+		if i < len(source) && !isWhitespace(source[i]) && source[i] != byte(newLine) {
+			if start < 0 {
+				start = i
 			}
+			continue
 		}
-		if !isValidFile {
-			return nil, ic, false
-		}
-		value += string(current)
-		cur++
-
-		current = source[cur]
 
-		if current > '8' || current < '1' {
-			return nil, ic, false
+		if start >= 0 {
+			tokens = append(tokens, wordToken(source[start:i], line, start-lineStart+1))
+			start = -1
 		}
-		value += string(current)
-		cur++
-	}
-
-	// promotion?
-	if cursor(len(source)) > cur {
-		current := source[cur]
-		if current != ' ' && current != '\n' {
-			isValidPiece := false
-			for _, p := range validPiecesForPromotion {
-				if current == p {
-					isValidPiece = true
-					break
-				}
-			}
-			if !isValidPiece {
-				return nil, ic, false
-			}
-			value += string(current)
-			cur++
+		if i < len(source) && source[i] == byte(newLine) {
+			tokens = append(tokens, &token{kind: symbolKind, value: string(newLine), text: string(newLine), line: line, column: i - lineStart + 1})
+			line++
+			lineStart = i + 1
 		}
 	}
 
-	return &token{
-		kind:  longAlgebraicNotation,
-		value: value,
-	}, cur, true
+	return tokens
 }
 
-func symbolLexer(source string, ic cursor) (*token, cursor, bool) {
-	cur := ic
-
-	current := source[ic]
-
-	for _, sym := range []symbol{newLine} {
-		if current == byte(sym) {
-			return &token{
-				kind:  symbolKind,
-				value: string(sym),
-			}, cur + 1, true
-		}
-	}
-
-	return nil, ic, false
+// isWhitespace tells whether the character separates words. A carriage return is white space, so
+// that lines ending with \r\n work as well.
+func isWhitespace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r'
 }
 
-func keywordLexer(incSource string, ic cursor) (*token, cursor, bool) {
-	cur := ic
-
-	source := strings.ToLower(incSource)
-
-	keywords := []keyword{value, binc, btime, code, debug, depth, fen, go_, infinite, isready, later, mate, moves, movestogo, movetime, name, nodes, off, on, ponder, ponderhit, position, quit, register, searchmoves, setoption, startpos, stop, uci, ucinewgame, winc, wtime}
-
-	sort.Slice(keywords, func(a int, b int) bool {
-		return len(string(keywords[a])) > len(string(keywords[b]))
-	})
-
-	for _, kw := range keywords {
-		if strings.HasPrefix(source[cur:], string(kw)) {
-			return &token{
-				kind:  keywordKind,
-				value: string(kw),
-			}, cur + cursor(len(string(kw))), true
-		}
+func wordToken(word string, line int, column int) *token {
+	t := &token{kind: stringKind, value: word, text: word, line: line, column: column}
+	if lower := strings.ToLower(word); keywords[keyword(lower)] {
+		t.kind = keywordKind
+		t.value = lower
+	} else if isLongAlgebraicNotation(word) {
+		t.kind = longAlgebraicNotation
 	}
 
-	return nil, ic, false
+	return t
 }
 
-func eatWhitespace(source string, ic cursor) cursor {
-	cur := ic
-
-	for cur < cursor(len(source)) && source[cur] == ' ' {
-		cur++
+// isLongAlgebraicNotation reports whether the word is a move like e2e4 or e7e8q, or the null move 0000.
+func isLongAlgebraicNotation(word string) bool {
+	if word == "0000" {
+		return true
+	}
+	if len(word) != 4 && len(word) != 5 {
+		return false
+	}
+	for i := 0; i < 4; i += 2 {
+		if word[i] < 'a' || word[i] > 'h' || word[i+1] < '1' || word[i+1] > '8' {
+			return false
+		}
 	}
 
-	return cur
+	return len(word) == 4 || strings.IndexByte("nbrq", word[4]) >= 0
 }
@@ -5,7 +5,7 @@ import (
 )
 
 func TestAlgebraicNotation(t *testing.T) {
-	tokens, _ := Lex("e8f8 a7e2q")
+	tokens := Lex("e8f8 a7e2q")
 
 	expectedTokens := []*token{
 		{
@@ -32,7 +32,7 @@ func TestKeywords(t *testing.T) {
 		input += " " + keywrd
 	}
 
-	actualList, _ := Lex(input)
+	actualList := Lex(input)
 
 	if len(actualList) != len(keywords) {
 		t.Error("Unexpected number of tokens")
@@ -53,10 +53,7 @@ func TestCaseInsensitiveKeywords(t *testing.T) {
 	expectedKeywords := []keyword{name, value}
 
 	for i, caseToTest := range cases {
-		actualList, err := Lex(caseToTest)
-		if err != nil {
-			t.Fatal("error lexing input:", err)
-		}
+		actualList := Lex(caseToTest)
 
 		if len(actualList) != 3 {
 			t.Fatal("expected 3 keywords, but got", len(actualList))
@@ -74,7 +71,7 @@ func TestCaseInsensitiveKeywords(t *testing.T) {
 }
 
 func TestIgnoreSpace(t *testing.T) {
-	actual, _ := Lex("go          e8f8 e8f8")
+	actual := Lex("go          e8f8 e8f8")
 
 	if len(actual) != 3 {
 		t.Errorf("Expected 3 tokens but got %d", len(actual))
@@ -82,20 +79,20 @@ func TestIgnoreSpace(t *testing.T) {
 }
 
 func TestSymbols(t *testing.T) {
-	actualList, _ := Lex("go e8f8\n")
+	actualList := Lex("go e8f8\n")
 
 	if len(actualList) != 3 {
 		t.Error("Expected 3 tokens, but got", len(actualList))
 	}
 
-	expectedNewLine := &token{symbolKind, "\n"}
+	expectedNewLine := &token{kind: symbolKind, value: "\n"}
 	if !actualList[2].equals(expectedNewLine) {
 		t.Errorf("Expected %v, but got %v", expectedNewLine, actualList[3])
 	}
 }
 
 func TestStrings(t *testing.T) {
-	actualList, _ := Lex("string1 c:\\path\\string2;string3")
+	actualList := Lex("string1 c:\\path\\string2;string3")
 
 	if len(actualList) != 2 {
 		t.Error("Expected 2 tokens, but got", len(actualList))
@@ -115,4 +112,40 @@ func TestStrings(t *testing.T) {
 	}
 }
 
+func TestWordBoundaries(t *testing.T) {
+	actualList := Lex("positional gone nodes2 e2e4x")
+
+	if len(actualList) != 4 {
+		t.Fatal("Expected 4 tokens, but got", len(actualList))
+	}
+	for _, actual := range actualList {
+		if actual.kind != stringKind {
+			t.Errorf("Expected %q to be a string, but got kind %d", actual.value, actual.kind)
+		}
+	}
+}
 
+func TestTokenPositions(t *testing.T) {
+	actualList := Lex("uci\r\n  go\tdepth 5\n")
+
+	expected := []struct {
+		value  string
+		line   int
+		column int
+	}{
+		{"uci", 1, 1},
+		{"\n", 1, 5},
+		{"go", 2, 3},
+		{"depth", 2, 6},
+		{"5", 2, 12},
+		{"\n", 2, 13},
+	}
+	if len(actualList) != len(expected) {
+		t.Fatal("Expected", len(expected), "tokens, but got", len(actualList))
+	}
+	for i, actual := range actualList {
+		if actual.value != expected[i].value || actual.line != expected[i].line || actual.column != expected[i].column {
+			t.Errorf("Expected %q at %d:%d, but got %q at %d:%d", expected[i].value, expected[i].line, expected[i].column, actual.value, actual.line, actual.column)
+		}
+	}
+}
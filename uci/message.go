@@ -0,0 +1,257 @@
+package uci
+
+import (
+	"strconv"
+	"strings"
+)
+
+type MessageKind string
+
+const (
+	IdMessageKind             MessageKind = "id"
+	UciOkMessageKind          MessageKind = "uciok"
+	ReadyOkMessageKind        MessageKind = "readyok"
+	BestMoveMessageKind       MessageKind = "bestmove"
+	CopyProtectionMessageKind MessageKind = "copyprotection"
+	RegistrationMessageKind   MessageKind = "registration"
+	InfoMessageKind           MessageKind = "info"
+	OptionMessageKind         MessageKind = "option"
+)
+
+// Message is sent from the engine to the GUI, one per line. Like Statement, it has the fields of
+// its kind set.
+type Message struct {
+	Kind     MessageKind
+	Id       *IdMessage
+	BestMove *BestMoveMessage
+	// Status is the state of the copy protection or the registration check
+	Status Status
+	Info   *InfoMessage
+	Option *OptionMessage
+}
+
+// IdMessage identifies the engine. Only one of the fields is set, name and author are sent as
+// separate messages.
+type IdMessage struct {
+	Name   string
+	Author string
+}
+
+type BestMoveMessage struct {
+	Move string
+	// Ponder is the move the engine would like to ponder on, empty if there is none.
+	Ponder string
+}
+
+type Status string
+
+const (
+	STATUS_CHECKING Status = "checking"
+	STATUS_OK       Status = "ok"
+	STATUS_ERROR    Status = "error"
+)
+
+type OptionType string
+
+const (
+	CHECK  OptionType = "check"
+	SPIN   OptionType = "spin"
+	COMBO  OptionType = "combo"
+	BUTTON OptionType = "button"
+	STRING OptionType = "string"
+)
+
+// emptyString is sent as the default of a string option without value.
+const emptyString = "<empty>"
+
+type OptionMessage struct {
+	Name string
+	Type OptionType
+	// Default is not sent for buttons.
+	Default string
+	// Min and Max are only sent for spin options.
+	Min int
+	Max int
+	// Vars are the values a combo option can take.
+	Vars []string
+}
+
+type InfoKind int
+
+// the info kinds are in the order they are sent
+const (
+	Info_depthKind InfoKind = iota
+	Info_selDepthKind
+	Info_multiPVKind
+	Info_scoreKind
+	Info_nodesKind
+	Info_npsKind
+	Info_timeKind
+	Info_hashFullKind
+	Info_tbHitsKind
+	Info_cpuLoadKind
+	Info_currMoveKind
+	Info_currMoveNumberKind
+	Info_refutationKind
+	Info_currLineKind
+	Info_pvKind
+	Info_stringKind
+)
+
+// InfoMessage tells the GUI what the engine is thinking. Kinds lists the fields that are set, like
+// GoStatement.Kinds does.
+type InfoMessage struct {
+	Kinds    []InfoKind
+	Depth    int
+	SelDepth int
+	// Time is in milliseconds.
+	Time  int
+	Nodes int
+	PV    []string
+	// MultiPV is the number of the line, starting at 1.
+	MultiPV        int
+	Score          Score
+	CurrMove       string
+	CurrMoveNumber int
+	// HashFull and CPULoad are in permill.
+	HashFull int
+	NPS      int
+	TBHits   int
+	CPULoad  int
+	// Text is the string, it is always sent last, as it takes the rest of the line.
+	Text       string
+	Refutation []string
+	// CPUNumber is the cpu calculating the CurrLine, 0 if the engine uses only one.
+	CPUNumber int
+	CurrLine  []string
+}
+
+// Score is in centipawns from the point of view of the engine, or the moves to mate if IsMate is
+// set. Mate is negative if the engine gets mated.
+type Score struct {
+	Centipawns int
+	Mate       int
+	IsMate     bool
+	// the score is only a bound if the search failed high or low
+	LowerBound bool
+	UpperBound bool
+}
+
+func (s Score) String() string {
+	out := "cp " + strconv.Itoa(s.Centipawns)
+	if s.IsMate {
+		out = "mate " + strconv.Itoa(s.Mate)
+	}
+	switch {
+	case s.LowerBound:
+		out += " lowerbound"
+	case s.UpperBound:
+		out += " upperbound"
+	}
+
+	return out
+}
+
+// Has tells whether the field of the kind is set.
+func (m *InfoMessage) Has(kind InfoKind) bool {
+	for _, k := range m.Kinds {
+		if k == kind {
+			return true
+		}
+	}
+
+	return false
+}
+
+// String returns the message as it is sent, without the new line.
+func (m *Message) String() string {
+	switch m.Kind {
+	case IdMessageKind:
+		if m.Id.Name != "" {
+			return "id name " + m.Id.Name
+		}
+		return "id author " + m.Id.Author
+	case BestMoveMessageKind:
+		out := "bestmove " + m.BestMove.Move
+		if m.BestMove.Ponder != "" {
+			out += " ponder " + m.BestMove.Ponder
+		}
+		return out
+	case CopyProtectionMessageKind, RegistrationMessageKind:
+		return string(m.Kind) + " " + string(m.Status)
+	case InfoMessageKind:
+		return m.Info.String()
+	case OptionMessageKind:
+		return m.Option.String()
+	}
+
+	return string(m.Kind)
+}
+
+func (m *OptionMessage) String() string {
+	words := []string{"option", "name", m.Name, "type", string(m.Type)}
+	if m.Type != BUTTON {
+		def := m.Default
+		if def == "" && m.Type == STRING {
+			def = emptyString
+		}
+		words = append(words, "default", def)
+	}
+	if m.Type == SPIN {
+		words = append(words, "min", strconv.Itoa(m.Min), "max", strconv.Itoa(m.Max))
+	}
+	for _, v := range m.Vars {
+		words = append(words, "var", v)
+	}
+
+	return strings.Join(words, " ")
+}
+
+func (m *InfoMessage) String() string {
+	words := []string{"info"}
+	for kind := Info_depthKind; kind <= Info_stringKind; kind++ {
+		if !m.Has(kind) {
+			continue
+		}
+		switch kind {
+		case Info_depthKind:
+			words = append(words, "depth", strconv.Itoa(m.Depth))
+		case Info_selDepthKind:
+			words = append(words, "seldepth", strconv.Itoa(m.SelDepth))
+		case Info_multiPVKind:
+			words = append(words, "multipv", strconv.Itoa(m.MultiPV))
+		case Info_scoreKind:
+			words = append(words, "score", m.Score.String())
+		case Info_nodesKind:
+			words = append(words, "nodes", strconv.Itoa(m.Nodes))
+		case Info_npsKind:
+			words = append(words, "nps", strconv.Itoa(m.NPS))
+		case Info_timeKind:
+			words = append(words, "time", strconv.Itoa(m.Time))
+		case Info_hashFullKind:
+			words = append(words, "hashfull", strconv.Itoa(m.HashFull))
+		case Info_tbHitsKind:
+			words = append(words, "tbhits", strconv.Itoa(m.TBHits))
+		case Info_cpuLoadKind:
+			words = append(words, "cpuload", strconv.Itoa(m.CPULoad))
+		case Info_currMoveKind:
+			words = append(words, "currmove", m.CurrMove)
+		case Info_currMoveNumberKind:
+			words = append(words, "currmovenumber", strconv.Itoa(m.CurrMoveNumber))
+		case Info_refutationKind:
+			words = append(append(words, "refutation"), m.Refutation...)
+		case Info_currLineKind:
+			words = append(words, "currline")
+			if m.CPUNumber > 0 {
+				words = append(words, strconv.Itoa(m.CPUNumber))
+			}
+			words = append(words, m.CurrLine...)
+		case Info_pvKind:
+			words = append(append(words, "pv"), m.PV...)
+		case Info_stringKind:
+			words = append(words, "string", m.Text)
+		}
+	}
+
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,310 @@
+package uci
+
+import (
+	"strconv"
+)
+
+type messageParser func(p *lineParser) (*Message, error)
+
+var messageParsers = map[keyword]messageParser{
+	id:             parseIdMessage,
+	uciok:          singleKeywordMessageParser(UciOkMessageKind),
+	readyok:        singleKeywordMessageParser(ReadyOkMessageKind),
+	bestmove:       parseBestMoveMessage,
+	copyprotection: statusMessageParser(CopyProtectionMessageKind),
+	registration:   statusMessageParser(RegistrationMessageKind),
+	info:           parseInfoMessage,
+	option:         parseOptionMessage,
+}
+
+// ParseMessages parses the output of an engine, one message per line. It handles errors like Parse
+// does: the messages of all good lines are returned together with the error of the first bad one.
+func ParseMessages(source string) ([]*Message, error) {
+	var messages []*Message
+	var firstErr error
+
+	for _, line := range splitLines(Lex(source)) {
+		msg, err := parseMessageLine(line)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		if msg != nil {
+			messages = append(messages, msg)
+		}
+	}
+
+	return messages, firstErr
+}
+
+func parseMessageLine(tokens []*token) (*Message, error) {
+	for i, t := range tokens {
+		if t.kind != keywordKind {
+			continue
+		}
+		parse, ok := messageParsers[keyword(t.value)]
+		if !ok {
+			continue
+		}
+
+		return parse(&lineParser{command: t, tokens: tokens[i+1:]})
+	}
+
+	return nil, nil
+}
+
+func singleKeywordMessageParser(kind MessageKind) messageParser {
+	return func(p *lineParser) (*Message, error) {
+		return &Message{Kind: kind}, nil
+	}
+}
+
+func statusMessageParser(kind MessageKind) messageParser {
+	return func(p *lineParser) (*Message, error) {
+		t := p.next()
+		if t == nil || !t.is(checking) && !t.is(ok) && !t.is(error_) {
+			return nil, p.errorAt(t, "expected checking, ok or error")
+		}
+
+		return &Message{Kind: kind, Status: Status(t.value)}, nil
+	}
+}
+
+func parseIdMessage(p *lineParser) (*Message, error) {
+	msg := &IdMessage{}
+	switch t := p.next(); {
+	case t != nil && t.is(name):
+		msg.Name = p.textUntil()
+		if msg.Name == "" {
+			return nil, p.errorAt(nil, "expected the name of the engine")
+		}
+	case t != nil && t.is(author):
+		msg.Author = p.textUntil()
+		if msg.Author == "" {
+			return nil, p.errorAt(nil, "expected the author of the engine")
+		}
+	default:
+		return nil, p.errorAt(t, "expected name or author")
+	}
+
+	return &Message{Kind: IdMessageKind, Id: msg}, nil
+}
+
+func parseBestMoveMessage(p *lineParser) (*Message, error) {
+	t := p.next()
+	if t == nil || t.kind != longAlgebraicNotation {
+		return nil, p.errorAt(t, "expected a move in long algebraic notation")
+	}
+	msg := &BestMoveMessage{Move: t.value}
+
+	if p.nextIs(ponder) {
+		t = p.next()
+		if t == nil || t.kind != longAlgebraicNotation {
+			return nil, p.errorAt(t, "expected a move to ponder on")
+		}
+		msg.Ponder = t.value
+	}
+
+	return &Message{Kind: BestMoveMessageKind, BestMove: msg}, nil
+}
+
+var optionTypes = map[string]OptionType{
+	string(CHECK):  CHECK,
+	string(SPIN):   SPIN,
+	string(COMBO):  COMBO,
+	string(BUTTON): BUTTON,
+	string(STRING): STRING,
+}
+
+func parseOptionMessage(p *lineParser) (*Message, error) {
+	if !p.nextIs(name) {
+		return nil, p.errorAt(p.peek(), "expected name")
+	}
+	msg := &OptionMessage{Name: p.textUntil(type_)}
+	if msg.Name == "" {
+		return nil, p.errorAt(p.peek(), "expected the name of the option")
+	}
+	if !p.nextIs(type_) {
+		return nil, p.errorAt(nil, "expected type")
+	}
+	t := p.next()
+	if t == nil {
+		return nil, p.errorAt(nil, "expected the type of the option")
+	}
+	optionType, found := optionTypes[t.value]
+	if !found {
+		return nil, p.errorAt(t, "unknown option type %s", t.text)
+	}
+	msg.Type = optionType
+
+	for !p.done() {
+		t := p.next()
+		switch {
+		case t.is(default_):
+			msg.Default = p.textUntil(min, max, var_)
+			if msg.Type == STRING && msg.Default == emptyString {
+				msg.Default = ""
+			}
+		case t.is(min), t.is(max):
+			number, err := p.number(t)
+			if err != nil {
+				return nil, err
+			}
+			if t.is(min) {
+				msg.Min = number
+			} else {
+				msg.Max = number
+			}
+		case t.is(var_):
+			msg.Vars = append(msg.Vars, p.textUntil(default_, min, max, var_))
+		}
+	}
+
+	return &Message{Kind: OptionMessageKind, Option: msg}, nil
+}
+
+var infoIntParameters = map[keyword]InfoKind{
+	depth:          Info_depthKind,
+	seldepth:       Info_selDepthKind,
+	time_:          Info_timeKind,
+	nodes:          Info_nodesKind,
+	multipv:        Info_multiPVKind,
+	currmovenumber: Info_currMoveNumberKind,
+	hashfull:       Info_hashFullKind,
+	nps:            Info_npsKind,
+	tbhits:         Info_tbHitsKind,
+	cpuload:        Info_cpuLoadKind,
+}
+
+func parseInfoMessage(p *lineParser) (*Message, error) {
+	msg := &InfoMessage{
+		Kinds: make([]InfoKind, 0),
+	}
+
+	for !p.done() {
+		t := p.next()
+		if kind, found := infoIntParameters[keyword(t.value)]; found && t.kind == keywordKind {
+			number, err := p.number(t)
+			if err != nil {
+				return nil, err
+			}
+			msg.Kinds = append(msg.Kinds, kind)
+			msg.setInt(kind, number)
+			continue
+		}
+
+		switch {
+		case t.is(score):
+			if err := p.parseScore(&msg.Score); err != nil {
+				return nil, err
+			}
+			msg.Kinds = append(msg.Kinds, Info_scoreKind)
+		case t.is(currmove):
+			move := p.next()
+			if move == nil || move.kind != longAlgebraicNotation {
+				return nil, p.errorAt(move, "expected a move after currmove")
+			}
+			msg.Kinds = append(msg.Kinds, Info_currMoveKind)
+			msg.CurrMove = move.value
+		case t.is(pv):
+			msg.Kinds = append(msg.Kinds, Info_pvKind)
+			msg.PV = p.moves()
+		case t.is(refutation):
+			msg.Kinds = append(msg.Kinds, Info_refutationKind)
+			msg.Refutation = p.moves()
+		case t.is(currline):
+			msg.Kinds = append(msg.Kinds, Info_currLineKind)
+			// the number of the cpu is optional
+			if cpu := p.peek(); cpu != nil && cpu.kind == stringKind {
+				if number, err := strconv.Atoi(cpu.text); err == nil {
+					p.next()
+					msg.CPUNumber = number
+				}
+			}
+			msg.CurrLine = p.moves()
+		case t.is(string_):
+			// the string is the rest of the line
+			msg.Kinds = append(msg.Kinds, Info_stringKind)
+			msg.Text = p.textUntil()
+		}
+		// anything else is an unknown token, which is skipped
+	}
+
+	return &Message{Kind: InfoMessageKind, Info: msg}, nil
+}
+
+// number reads the number following the keyword token.
+func (p *lineParser) number(keywordToken *token) (int, error) {
+	t := p.next()
+	if t == nil {
+		return 0, p.errorAt(nil, "expected a number after %s", keywordToken.value)
+	}
+	number, err := strconv.Atoi(t.text)
+	if err != nil {
+		return 0, p.errorAt(t, "expected a number after %s, but found %s", keywordToken.value, t.text)
+	}
+
+	return number, nil
+}
+
+// moves reads the following moves in long algebraic notation.
+func (p *lineParser) moves() []string {
+	var moves []string
+	for !p.done() && p.peek().kind == longAlgebraicNotation {
+		moves = append(moves, p.next().value)
+	}
+
+	return moves
+}
+
+func (p *lineParser) parseScore(s *Score) error {
+	t := p.next()
+	if t == nil || !t.is(cp) && !t.is(mate) {
+		return p.errorAt(t, "expected cp or mate")
+	}
+	number, err := p.number(t)
+	if err != nil {
+		return err
+	}
+	if t.is(mate) {
+		s.IsMate, s.Mate = true, number
+	} else {
+		s.Centipawns = number
+	}
+
+	switch {
+	case p.nextIs(lowerbound):
+		s.LowerBound = true
+	case p.nextIs(upperbound):
+		s.UpperBound = true
+	}
+
+	return nil
+}
+
+func (m *InfoMessage) setInt(kind InfoKind, number int) {
+	switch kind {
+	case Info_depthKind:
+		m.Depth = number
+	case Info_selDepthKind:
+		m.SelDepth = number
+	case Info_timeKind:
+		m.Time = number
+	case Info_nodesKind:
+		m.Nodes = number
+	case Info_multiPVKind:
+		m.MultiPV = number
+	case Info_currMoveNumberKind:
+		m.CurrMoveNumber = number
+	case Info_hashFullKind:
+		m.HashFull = number
+	case Info_npsKind:
+		m.NPS = number
+	case Info_tbHitsKind:
+		m.TBHits = number
+	case Info_cpuLoadKind:
+		m.CPULoad = number
+	}
+}
@@ -0,0 +1,129 @@
+package uci
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestMessageString(t *testing.T) {
+	expectations := []struct {
+		msg      *Message
+		expected string
+	}{
+		{&Message{Kind: IdMessageKind, Id: &IdMessage{Name: "Outstanding Move"}}, "id name Outstanding Move"},
+		{&Message{Kind: IdMessageKind, Id: &IdMessage{Author: "The Authors"}}, "id author The Authors"},
+		{&Message{Kind: UciOkMessageKind}, "uciok"},
+		{&Message{Kind: ReadyOkMessageKind}, "readyok"},
+		{&Message{Kind: BestMoveMessageKind, BestMove: &BestMoveMessage{Move: "e2e4"}}, "bestmove e2e4"},
+		{&Message{Kind: BestMoveMessageKind, BestMove: &BestMoveMessage{Move: "e7e8q", Ponder: "d8e8"}}, "bestmove e7e8q ponder d8e8"},
+		{&Message{Kind: CopyProtectionMessageKind, Status: STATUS_CHECKING}, "copyprotection checking"},
+		{&Message{Kind: RegistrationMessageKind, Status: STATUS_ERROR}, "registration error"},
+		{
+			&Message{Kind: OptionMessageKind, Option: &OptionMessage{Name: "Hash", Type: SPIN, Default: "16", Min: 1, Max: 1024}},
+			"option name Hash type spin default 16 min 1 max 1024",
+		},
+		{&Message{Kind: OptionMessageKind, Option: &OptionMessage{Name: "Clear Hash", Type: BUTTON}}, "option name Clear Hash type button"},
+		{&Message{Kind: OptionMessageKind, Option: &OptionMessage{Name: "NalimovPath", Type: STRING}}, "option name NalimovPath type string default <empty>"},
+		{
+			&Message{Kind: OptionMessageKind, Option: &OptionMessage{Name: "Style", Type: COMBO, Default: "Normal", Vars: []string{"Solid", "Normal", "Risky"}}},
+			"option name Style type combo default Normal var Solid var Normal var Risky",
+		},
+		{
+			&Message{Kind: InfoMessageKind, Info: &InfoMessage{
+				Kinds: []InfoKind{Info_pvKind, Info_depthKind, Info_scoreKind, Info_nodesKind, Info_timeKind, Info_hashFullKind},
+				Depth: 3, Score: Score{Mate: -2, IsMate: true}, Nodes: 1200, Time: 15, HashFull: 4, PV: []string{"e2e4", "e7e5"},
+			}},
+			"info depth 3 score mate -2 nodes 1200 time 15 hashfull 4 pv e2e4 e7e5",
+		},
+		{
+			&Message{Kind: InfoMessageKind, Info: &InfoMessage{
+				Kinds: []InfoKind{Info_scoreKind, Info_currMoveKind, Info_currMoveNumberKind},
+				Score: Score{Centipawns: 25, LowerBound: true}, CurrMove: "g1f3", CurrMoveNumber: 2,
+			}},
+			"info score cp 25 lowerbound currmove g1f3 currmovenumber 2",
+		},
+		{
+			&Message{Kind: InfoMessageKind, Info: &InfoMessage{Kinds: []InfoKind{Info_stringKind}, Text: "depth is no keyword here"}},
+			"info string depth is no keyword here",
+		},
+	}
+
+	for _, expectation := range expectations {
+		if actual := expectation.msg.String(); actual != expectation.expected {
+			t.Errorf("expected %q, but got %q", expectation.expected, actual)
+		}
+	}
+}
+
+func TestParseMessagesRoundTrip(t *testing.T) {
+	lines := []string{
+		"id name Outstanding Move",
+		"id author The Authors",
+		"uciok",
+		"readyok",
+		"bestmove e2e4",
+		"bestmove e7e8q ponder d8e8",
+		"copyprotection ok",
+		"registration checking",
+		"option name Hash type spin default 16 min 1 max 1024",
+		"option name Clear Hash type button",
+		"option name NalimovPath type string default <empty>",
+		"option name Nullmove type check default true",
+		"option name Style type combo default Normal var Solid var Normal var Risky",
+		"info depth 12 seldepth 20 multipv 2 score cp -31 upperbound nodes 123456 nps 98765 time 1250 hashfull 450 tbhits 3 cpuload 870 pv e2e4 e7e5 g1f3",
+		"info currmove e2e4 currmovenumber 1",
+		"info refutation d1h5 g6h5",
+		"info currline 1 d1h5 g6h5",
+		"info string Mate in 3 depth 5",
+	}
+
+	for _, line := range lines {
+		messages, err := ParseMessages(line + "\n")
+		if err != nil {
+			t.Fatalf("error parsing %q: %v", line, err)
+		}
+		if len(messages) != 1 {
+			t.Fatalf("expected 1 message from %q, but got %d", line, len(messages))
+		}
+		if actual := messages[0].String(); actual != line {
+			t.Errorf("expected %q, but got %q", line, actual)
+		}
+	}
+}
+
+func TestParseInfoMessage(t *testing.T) {
+	messages, err := ParseMessages("info depth 2 unknown 7 score mate 3 pv a1a8 b8b7\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	info := messages[0].Info
+
+	if !reflect.DeepEqual(info.Kinds, []InfoKind{Info_depthKind, Info_scoreKind, Info_pvKind}) {
+		t.Errorf("expected depth, score and pv, but got %v", info.Kinds)
+	}
+	if info.Depth != 2 || !info.Score.IsMate || info.Score.Mate != 3 {
+		t.Errorf("expected depth 2 and mate in 3, but got %+v", info)
+	}
+	if !reflect.DeepEqual(info.PV, []string{"a1a8", "b8b7"}) {
+		t.Errorf("expected the pv a1a8 b8b7, but got %v", info.PV)
+	}
+	if info.Has(Info_nodesKind) {
+		t.Error("expected no nodes")
+	}
+}
+
+func TestParseMessagesErrors(t *testing.T) {
+	messages, err := ParseMessages("uciok\nbestmove\ninfo depth x\nreadyok\n")
+
+	var parseErr *Error
+	if !errors.As(err, &parseErr) {
+		t.Fatalf("expected a parse error, but got %v", err)
+	}
+	if parseErr.Line != 2 || parseErr.Command != "bestmove" {
+		t.Errorf("expected the error in the bestmove of line 2, but got %v", parseErr)
+	}
+	if len(messages) != 2 || messages[0].Kind != UciOkMessageKind || messages[1].Kind != ReadyOkMessageKind {
+		t.Errorf("expected the messages of the good lines, but got %v", messages)
+	}
+}
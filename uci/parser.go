@@ -9,17 +9,17 @@ import (
 type StatementKind string
 
 const (
-	UciStatementKind StatementKind = "uci"
-	DebugStatementKind = "debug"
-	IsReadyStatementKind = "isReady"
-	SetOptionStatementKind = "setOption"
-	RegisterStatementKind = "register"
-	UciNewGameStatementKind = "uciNewGame"
-	PositionStatementKind = "position"
-	GoStatementKind = "go"
-	StopStatementKind = "stop"
-	PonderHitStatementKind = "ponderHit"
-	QuitStatementKind = "quit"
+	UciStatementKind        StatementKind = "uci"
+	DebugStatementKind                    = "debug"
+	IsReadyStatementKind                  = "isReady"
+	SetOptionStatementKind                = "setOption"
+	RegisterStatementKind                 = "register"
+	UciNewGameStatementKind               = "uciNewGame"
+	PositionStatementKind                 = "position"
+	GoStatementKind                       = "go"
+	StopStatementKind                     = "stop"
+	PonderHitStatementKind                = "ponderHit"
+	QuitStatementKind                     = "quit"
 )
 
 type GoKind int
@@ -84,450 +84,317 @@ type DebugStatement struct {
 	On bool
 }
 
-func Parse(source string) ([]*Statement, error) {
-	var statements []*Statement
-	tokens, err := Lex(source)
-	if err != nil {
-		return statements, err
-	}
+// Error tells where a line could not be parsed.
+type Error struct {
+	Line    int
+	Column  int
+	Command string
+	Message string
+}
 
-	var cursor uint
+func (e *Error) Error() string {
+	return fmt.Sprintf("line %d, column %d: %s: %s", e.Line, e.Column, e.Command, e.Message)
+}
 
-	for cursor < uint(len(tokens)) {
-		cursor = eatNonKeywords(tokens, cursor)
-		if cursor == uint(len(tokens))-1 {
-			break
-		}
+type commandParser func(p *lineParser) (*Statement, error)
+
+var commandParsers = map[keyword]commandParser{
+	uci:        singleKeywordParser(UciStatementKind),
+	debug:      parseDebugStatement,
+	isready:    singleKeywordParser(IsReadyStatementKind),
+	setoption:  parseSetOptionStatement,
+	register:   parseRegisterStatement,
+	ucinewgame: singleKeywordParser(UciNewGameStatementKind),
+	position:   parsePositionStatement,
+	go_:        parseGoStatement,
+	stop:       singleKeywordParser(StopStatementKind),
+	ponderhit:  singleKeywordParser(PonderHitStatementKind),
+	quit:       singleKeywordParser(QuitStatementKind),
+}
 
-		// uci statement
-		newCursor, ok := parseSingleKeywordStatement(tokens, cursor, uci)
-		if ok {
-			cursor = newCursor
-			statements = append(statements, &Statement{
-				Kind: UciStatementKind,
-			})
-			if newCursor == uint(len(tokens)) {
-				break
-			}
-		}
+// Parse parses one statement per line. Unknown words in front of the command are skipped, as are
+// lines without any command. A line that cannot be parsed is left out, the error is the *Error of
+// the first such line, and the statements of all other lines are returned nevertheless.
+func Parse(source string) ([]*Statement, error) {
+	var statements []*Statement
+	var firstErr error
 
-		// debug statement
-		debugStmnt, newCursor, ok, err := parseDebugStatement(tokens, cursor)
+	for _, line := range splitLines(Lex(source)) {
+		stmnt, err := parseLine(line)
 		if err != nil {
-			return statements, fmt.Errorf("error parsing debug statement: %s", err)
-		}
-		if ok {
-			cursor = newCursor
-			statements = append(statements, &Statement{
-				Kind:  DebugStatementKind,
-				Debug: debugStmnt,
-			})
-			if newCursor == uint(len(tokens)) {
-				break
-			}
-		}
-
-		//isReady statement
-		newCursor, ok = parseSingleKeywordStatement(tokens, cursor, isready)
-		if ok {
-			cursor = newCursor
-			statements = append(statements, &Statement{
-				Kind: IsReadyStatementKind,
-			})
-			if newCursor == uint(len(tokens)) {
-				break
+			if firstErr == nil {
+				firstErr = err
 			}
+			continue
 		}
-
-		// setOption statement
-		setOptionStmnt, newCursor, ok, err := parseSetOptionStatement(tokens, cursor)
-		if err != nil {
-			return statements, fmt.Errorf("error parsing setOption: %s", err)
-		}
-		if ok {
-			cursor = newCursor
-			statements = append(statements, &Statement{
-				Kind:      SetOptionStatementKind,
-				SetOption: setOptionStmnt,
-			})
-			if newCursor == uint(len(tokens)) {
-				break
-			}
+		if stmnt != nil {
+			statements = append(statements, stmnt)
 		}
+	}
 
-		// position statement
-		positionStmnt, newCursor, ok, err := parsePositionStatement(tokens, cursor)
-		if ok {
-			cursor = newCursor
-			statements = append(statements, &Statement{
-				Kind:     PositionStatementKind,
-				Position: positionStmnt,
-			})
-			if newCursor == uint(len(tokens)) {
-				break
-			}
-		}
+	return statements, firstErr
+}
 
-		// go statement
-		goStmnt, newCursor, ok, err := parseGoStatement(tokens, cursor)
-		if ok {
-			cursor = newCursor
-			statements = append(statements, &Statement{
-				Kind: GoStatementKind,
-				Go:   goStmnt,
-			})
-			if newCursor == uint(len(tokens)) {
-				break
-			}
+// splitLines returns the tokens of each line, without the new lines.
+func splitLines(tokens []*token) [][]*token {
+	var lines [][]*token
+	for len(tokens) > 0 {
+		end := 0
+		for end < len(tokens) && tokens[end].kind != symbolKind {
+			end++
 		}
-
-		// register statement
-		registerStmnt, newCursor, ok, err := parseRegisterStatement(tokens, cursor)
-		if ok {
-			cursor = newCursor
-			statements = append(statements, &Statement{
-				Kind:     RegisterStatementKind,
-				Register: registerStmnt,
-			})
-			if newCursor == uint(len(tokens)) {
-				break
-			}
+		lines = append(lines, tokens[:end])
+		if end < len(tokens) {
+			end++
 		}
+		tokens = tokens[end:]
+	}
 
-		// newGame Statement
-		newCursor, ok = parseSingleKeywordStatement(tokens, cursor, ucinewgame)
-		if ok {
-			cursor = newCursor
-			statements = append(statements, &Statement{
-				Kind: UciNewGameStatementKind,
-			})
-			if newCursor == uint(len(tokens)) {
-				break
-			}
-		}
+	return lines
+}
 
-		// stop Statement
-		newCursor, ok = parseSingleKeywordStatement(tokens, cursor, stop)
-		if ok {
-			cursor = newCursor
-			statements = append(statements, &Statement{
-				Kind: StopStatementKind,
-			})
-			if newCursor == uint(len(tokens)) {
-				break
-			}
+// parseLine dispatches on the first command of the line. It returns no statement if there is none.
+func parseLine(tokens []*token) (*Statement, error) {
+	for i, t := range tokens {
+		if t.kind != keywordKind {
+			continue
 		}
-
-		// ponderHit Statement
-		newCursor, ok = parseSingleKeywordStatement(tokens, cursor, ponderhit)
-		if ok {
-			cursor = newCursor
-			statements = append(statements, &Statement{
-				Kind: PonderHitStatementKind,
-			})
-			if newCursor == uint(len(tokens)) {
-				break
-			}
+		parse, ok := commandParsers[keyword(t.value)]
+		if !ok {
+			continue
 		}
 
-		// quit Statement
-		newCursor, ok = parseSingleKeywordStatement(tokens, cursor, quit)
-		if ok {
-			cursor = newCursor
-			statements = append(statements, &Statement{
-				Kind: QuitStatementKind,
-			})
-			if newCursor == uint(len(tokens)) {
-				break
-			}
-		}
+		return parse(&lineParser{command: t, tokens: tokens[i+1:]})
 	}
 
-	return statements, nil
+	return nil, nil
 }
 
-func parseRegisterStatement(tokens []*token, ic uint) (*RegisterStatement, uint, bool, error) {
-	if !tokens[ic].equals(tokenFromKeyword(go_)) {
-		return nil, ic, false, nil
-	}
-	cursor := ic
-
-	stmnt := &RegisterStatement{}
-	cursor++
+// lineParser reads the tokens following the command of a line.
+type lineParser struct {
+	command *token
+	tokens  []*token
+	cursor  int
+}
 
-	if tokens[cursor].equals(tokenFromKeyword(later)) {
-		stmnt.IsLater = true
-		cursor = eatAllToNextNewLine(tokens, cursor)
-		return stmnt, cursor, true, nil
-	}
+func (p *lineParser) done() bool {
+	return p.cursor >= len(p.tokens)
+}
 
-	for !tokens[cursor].equals(tokenFromSymbol(newLine)) && cursor < uint(len(tokens)) {
-		if tokens[cursor].equals(tokenFromKeyword(name)) {
-			var name []string
-			for !tokens[cursor].equals(tokenFromKeyword(code)) && !tokens[cursor].equals(tokenFromSymbol(newLine)) && cursor < uint(len(tokens)) {
-				name = append(name, tokens[cursor].value)
-				cursor++
-			}
-			stmnt.Name = strings.Join(name, " ")
-		}
-		if tokens[cursor].equals(tokenFromKeyword(code)) {
-			var code []string
-			for !tokens[cursor].equals(tokenFromKeyword(name)) && !tokens[cursor].equals(tokenFromSymbol(newLine)) && cursor < uint(len(tokens)) {
-				code = append(code, tokens[cursor].value)
-				cursor++
-			}
-			stmnt.Code = strings.Join(code, " ")
-		}
+// peek returns the next token, nil at the end of the line.
+func (p *lineParser) peek() *token {
+	if p.done() {
+		return nil
 	}
 
-	return stmnt, cursor, true, nil
+	return p.tokens[p.cursor]
 }
 
-func parseGoStatement(tokens []*token, ic uint) (*GoStatement, uint, bool, error) {
-	if !tokens[ic].equals(tokenFromKeyword(go_)) {
-		return nil, ic, false, nil
+func (p *lineParser) next() *token {
+	t := p.peek()
+	if t != nil {
+		p.cursor++
 	}
-	cursor := ic
 
-	stmnt := &GoStatement{
-		Kinds: make([]GoKind, 0),
-	}
+	return t
+}
 
-	parseIntGoStatement := func(kind GoKind, stmnt *GoStatement, ic uint) (int, uint, error) {
-		stmnt.Kinds = append(stmnt.Kinds, kind)
-		cursor := ic
-		cursor++
-		number, err := strconv.Atoi(tokens[cursor].value)
-		if err != nil {
-			return 0, ic, fmt.Errorf("expected number: %s", err)
-		}
-		cursor++
-		return number, cursor, nil
+// nextIs consumes the next token if it is the keyword.
+func (p *lineParser) nextIs(k keyword) bool {
+	if t := p.peek(); t != nil && t.is(k) {
+		p.cursor++
+		return true
 	}
 
-	var err error
-	var number int
-
-	cursor++
-	for cursor < uint(len(tokens)) && !tokens[cursor].equals(tokenFromSymbol(newLine)) {
-
-		switch tokens[cursor].value {
-
-		case string(searchmoves):
-			cursor++
-			stmnt.Kinds = append(stmnt.Kinds, Go_searchMovesKind)
-			var moves []string
-			for tokens[cursor].kind == longAlgebraicNotation {
-				moves = append(moves, tokens[cursor].value)
-				cursor++
-			}
-			stmnt.SearchMoves = moves
-
-		case string(ponder):
-			stmnt.Kinds = append(stmnt.Kinds, Go_ponderKind)
-			cursor++
-
-		case string(infinite):
-			stmnt.Kinds = append(stmnt.Kinds, Go_inifiniteKind)
-			cursor++
-
-		case string(wtime):
-			number, cursor, err = parseIntGoStatement(Go_wtimeKind, stmnt, cursor)
-			if err != nil {
-				return nil, ic, false, err
-			}
-			stmnt.Wtime = number
-
-		case string(btime):
-			number, cursor, err = parseIntGoStatement(Go_btimeKind, stmnt, cursor)
-			if err != nil {
-				return nil, ic, false, err
-			}
-			stmnt.Btime = number
-
-		case string(winc):
-			number, cursor, err = parseIntGoStatement(Go_wincKind, stmnt, cursor)
-			if err != nil {
-				return nil, ic, false, err
-			}
-			stmnt.Winc = number
-
-		case string(binc):
-			number, cursor, err = parseIntGoStatement(Go_bincKind, stmnt, cursor)
-			if err != nil {
-				return nil, ic, false, err
-			}
-			stmnt.Binc = number
-
-		case string(movestogo):
-			number, cursor, err = parseIntGoStatement(Go_movesToGoKind, stmnt, cursor)
-			if err != nil {
-				return nil, ic, false, err
-			}
-			stmnt.MovesToGo = number
-
-		case string(depth):
-			number, cursor, err = parseIntGoStatement(Go_depthKind, stmnt, cursor)
-			if err != nil {
-				return nil, ic, false, err
-			}
-			stmnt.Depth = number
-
-		case string(nodes):
-			number, cursor, err = parseIntGoStatement(Go_nodesKind, stmnt, cursor)
-			if err != nil {
-				return nil, ic, false, err
-			}
-			stmnt.Nodes = number
-
-		case string(mate):
-			number, cursor, err = parseIntGoStatement(Go_mateKind, stmnt, cursor)
-			if err != nil {
-				return nil, ic, false, err
-			}
-			stmnt.Mate = number
+	return false
+}
 
-		case string(movetime):
-			number, cursor, err = parseIntGoStatement(Go_moveTimeKind, stmnt, cursor)
-			if err != nil {
-				return nil, ic, false, err
+// textUntil joins the text of the tokens up to one of the keywords or the end of the line.
+func (p *lineParser) textUntil(stops ...keyword) string {
+	var words []string
+	for !p.done() {
+		for _, stop := range stops {
+			if p.peek().is(stop) {
+				return strings.Join(words, " ")
 			}
-			stmnt.MoveTime = number
 		}
+		words = append(words, p.next().text)
 	}
-	cursor++
 
-	return stmnt, cursor, true, nil
+	return strings.Join(words, " ")
 }
 
-func parsePositionStatement(tokens []*token, ic uint) (*PositionStatement, uint, bool, error) {
-	if !tokens[ic].equals(tokenFromKeyword(position)) {
-		return nil, ic, false, nil
-	}
-	cursor := ic
-	cursor++
-	stmnt := &PositionStatement{}
-	if tokens[cursor].equals(tokenFromKeyword(fen)) {
-		stmnt.IsFen = true
-		cursor++
-		stmnt.FenString = tokens[cursor].value
-		cursor++
-	}
-	if tokens[cursor].equals(tokenFromKeyword(startpos)) {
-		stmnt.IsStartPos = true
-		if stmnt.IsFen {
-			return nil, ic, false, fmt.Errorf("a position statement cannot have a fen string and a startpos flag")
-		}
-		cursor++
-	}
-
-	if !tokens[cursor].equals(tokenFromKeyword(moves)) {
-		return nil, ic, false, fmt.Errorf("expected 'moves' in position statement")
-	}
-	cursor++
-	var moves []string
-	for !tokens[cursor].equals(tokenFromSymbol(newLine)) && cursor < uint(len(tokens)) {
-		if tokens[cursor].kind != longAlgebraicNotation {
-			return nil, ic, false, fmt.Errorf("expected long algebraic notation string for moves, but found %s", tokens[cursor].value)
+// errorAt returns an error at the token, or right behind the last token of the line if it is nil.
+func (p *lineParser) errorAt(t *token, format string, args ...interface{}) error {
+	err := &Error{Command: p.command.value, Message: fmt.Sprintf(format, args...)}
+	if t == nil {
+		t = p.command
+		if len(p.tokens) > 0 {
+			t = p.tokens[len(p.tokens)-1]
 		}
-		moves = append(moves, tokens[cursor].value)
-		cursor++
+		err.Line, err.Column = t.line, t.column+len(t.text)
+		return err
 	}
-	stmnt.Moves = moves
-	cursor++
+	err.Line, err.Column = t.line, t.column
 
-	return stmnt, cursor, true, nil
+	return err
 }
 
-func parseSetOptionStatement(tokens []*token, cursor uint) (*SetOptionStatement, uint, bool, error) {
-	if !tokens[cursor].equals(tokenFromKeyword(setoption)) {
-		return nil, cursor, false, nil
+func singleKeywordParser(kind StatementKind) commandParser {
+	return func(p *lineParser) (*Statement, error) {
+		return &Statement{Kind: kind}, nil
 	}
+}
 
-	cursor++
-	if !tokens[cursor].equals(tokenFromKeyword(name)) {
-		return nil, cursor, false, fmt.Errorf("expected name after setOption")
+func parseDebugStatement(p *lineParser) (*Statement, error) {
+	t := p.next()
+	if t == nil || !t.is(on) && !t.is(off) {
+		return nil, p.errorAt(t, "expected on or off")
 	}
 
-	stmnt := &SetOptionStatement{}
+	return &Statement{Kind: DebugStatementKind, Debug: &DebugStatement{On: t.is(on)}}, nil
+}
 
-	cursor++
-	var nameParts []string
-	for !tokens[cursor].equals(tokenFromKeyword(value)) && !tokens[cursor].equals(tokenFromSymbol(newLine)) {
-		nameParts = append(nameParts, tokens[cursor].value)
-		cursor++
+func parseSetOptionStatement(p *lineParser) (*Statement, error) {
+	if !p.nextIs(name) {
+		return nil, p.errorAt(p.peek(), "expected name")
 	}
-	stmnt.Name = strings.Join(nameParts, " ")
-
-	if !tokens[cursor].equals(tokenFromKeyword(value)) {
-		cursor++
-		return stmnt, cursor, true, nil
+	stmnt := &SetOptionStatement{Name: p.textUntil(value)}
+	if stmnt.Name == "" {
+		return nil, p.errorAt(p.peek(), "expected the name of the option")
 	}
-	cursor++
-	var valueParts []string
-	for !tokens[cursor].equals(tokenFromSymbol(newLine)) {
-		valueParts = append(valueParts, tokens[cursor].value)
-		cursor++
+	if p.nextIs(value) {
+		stmnt.Value = p.textUntil()
 	}
-	stmnt.Value = strings.Join(valueParts, " ")
-	cursor++
 
-	return stmnt, cursor, true, nil
+	return &Statement{Kind: SetOptionStatementKind, SetOption: stmnt}, nil
 }
 
-func parseDebugStatement(tokens []*token, cursor uint) (*DebugStatement, uint, bool, error) {
-	if !tokens[cursor].equals(tokenFromKeyword(debug)) {
-		return nil, cursor, false, nil
+func parseRegisterStatement(p *lineParser) (*Statement, error) {
+	stmnt := &RegisterStatement{}
+	if p.nextIs(later) {
+		stmnt.IsLater = true
+		return &Statement{Kind: RegisterStatementKind, Register: stmnt}, nil
 	}
 
-	cursor++
-	if !tokens[cursor].equals(tokenFromKeyword(on)) || !tokens[cursor].equals(tokenFromKeyword(off)) {
-		return nil, cursor, false, fmt.Errorf("expected on or off after debug statement")
+	for !p.done() {
+		switch t := p.next(); {
+		case t.is(name):
+			stmnt.Name = p.textUntil(code)
+		case t.is(code):
+			stmnt.Code = p.textUntil(name)
+		}
+	}
+	if stmnt.Name == "" && stmnt.Code == "" {
+		return nil, p.errorAt(nil, "expected later, name or code")
 	}
-	onOffToken := tokens[cursor]
-
-	cursor = eatAllToNextNewLine(tokens, cursor)
 
-	return &DebugStatement{On: onOffToken.equals(tokenFromKeyword(on))}, cursor, true, nil
+	return &Statement{Kind: RegisterStatementKind, Register: stmnt}, nil
 }
 
-func tokenFromKeyword(k keyword) *token {
-	return &token{
-		kind:  keywordKind,
-		value: string(k),
+func parsePositionStatement(p *lineParser) (*Statement, error) {
+	stmnt := &PositionStatement{}
+	switch t := p.next(); {
+	case t != nil && t.is(startpos):
+		stmnt.IsStartPos = true
+	case t != nil && t.is(fen):
+		stmnt.IsFen = true
+		// the fields of the fen string are separate tokens, up to the moves or the end of the line
+		stmnt.FenString = p.textUntil(moves)
+		if stmnt.FenString == "" {
+			return nil, p.errorAt(p.peek(), "expected a fen string")
+		}
+	default:
+		return nil, p.errorAt(t, "expected startpos or fen")
 	}
-}
 
-func tokenFromSymbol(s symbol) *token {
-	return &token{
-		kind:  symbolKind,
-		value: string(s),
+	// the moves are optional
+	if p.done() {
+		return &Statement{Kind: PositionStatementKind, Position: stmnt}, nil
 	}
-}
-
-func eatAllToNextNewLine(tokens []*token, cursor uint) uint {
-	for cursor < uint(len(tokens))-1 && !tokens[cursor].equals(tokenFromSymbol(newLine)) {
-		cursor++
+	if t := p.next(); !t.is(moves) {
+		return nil, p.errorAt(t, "expected moves, but found %s", t.text)
+	}
+	for !p.done() {
+		t := p.next()
+		if t.kind != longAlgebraicNotation {
+			return nil, p.errorAt(t, "expected a move in long algebraic notation, but found %s", t.text)
+		}
+		stmnt.Moves = append(stmnt.Moves, t.value)
 	}
 
-	return cursor + 1
+	return &Statement{Kind: PositionStatementKind, Position: stmnt}, nil
 }
 
-func parseSingleKeywordStatement(tokens []*token, cursor uint, keyword keyword) (uint, bool) {
-	if !tokens[cursor].equals(tokenFromKeyword(keyword)) {
-		return cursor, false
+var goIntParameters = map[keyword]GoKind{
+	wtime:     Go_wtimeKind,
+	btime:     Go_btimeKind,
+	winc:      Go_wincKind,
+	binc:      Go_bincKind,
+	movestogo: Go_movesToGoKind,
+	depth:     Go_depthKind,
+	nodes:     Go_nodesKind,
+	mate:      Go_mateKind,
+	movetime:  Go_moveTimeKind,
+}
+
+func parseGoStatement(p *lineParser) (*Statement, error) {
+	stmnt := &GoStatement{
+		Kinds: make([]GoKind, 0),
 	}
 
-	cursor++
-	cursor = eatAllToNextNewLine(tokens, cursor)
+	for !p.done() {
+		t := p.next()
+		if kind, ok := goIntParameters[keyword(t.value)]; ok && t.kind == keywordKind {
+			numberToken := p.next()
+			if numberToken == nil {
+				return nil, p.errorAt(nil, "expected a number after %s", t.value)
+			}
+			number, err := strconv.Atoi(numberToken.text)
+			if err != nil {
+				return nil, p.errorAt(numberToken, "expected a number after %s, but found %s", t.value, numberToken.text)
+			}
+			stmnt.Kinds = append(stmnt.Kinds, kind)
+			stmnt.setInt(kind, number)
+			continue
+		}
+
+		switch {
+		case t.is(searchmoves):
+			stmnt.Kinds = append(stmnt.Kinds, Go_searchMovesKind)
+			for !p.done() && p.peek().kind == longAlgebraicNotation {
+				stmnt.SearchMoves = append(stmnt.SearchMoves, p.next().value)
+			}
+		case t.is(ponder):
+			stmnt.Kinds = append(stmnt.Kinds, Go_ponderKind)
+		case t.is(infinite):
+			stmnt.Kinds = append(stmnt.Kinds, Go_inifiniteKind)
+		}
+		// anything else is an unknown token, which is skipped
+	}
 
-	return cursor, true
+	return &Statement{Kind: GoStatementKind, Go: stmnt}, nil
 }
 
-func eatNonKeywords(tokens []*token, cursor uint) uint {
-	for uint(len(tokens)) < cursor && tokens[cursor].kind != keywordKind {
-		cursor++
+func (stmnt *GoStatement) setInt(kind GoKind, number int) {
+	switch kind {
+	case Go_wtimeKind:
+		stmnt.Wtime = number
+	case Go_btimeKind:
+		stmnt.Btime = number
+	case Go_wincKind:
+		stmnt.Winc = number
+	case Go_bincKind:
+		stmnt.Binc = number
+	case Go_movesToGoKind:
+		stmnt.MovesToGo = number
+	case Go_depthKind:
+		stmnt.Depth = number
+	case Go_nodesKind:
+		stmnt.Nodes = number
+	case Go_mateKind:
+		stmnt.Mate = number
+	case Go_moveTimeKind:
+		stmnt.MoveTime = number
 	}
-
-	return cursor
 }
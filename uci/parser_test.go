@@ -1,6 +1,8 @@
 package uci
 
 import (
+	"errors"
+	"strings"
 	"testing"
 )
 
@@ -118,3 +120,148 @@ func inKinds(kind GoKind, list []GoKind) bool {
 
 	return false
 }
+
+func TestPositionStatement(t *testing.T) {
+	expectations := []struct {
+		source     string
+		fenString  string
+		isFen      bool
+		isStartPos bool
+		moves      []string
+	}{
+		{
+			source:     "position startpos\n",
+			isStartPos: true,
+		},
+		{
+			source:     "position startpos moves e2e4 e7e5\n",
+			isStartPos: true,
+			moves:      []string{"e2e4", "e7e5"},
+		},
+		{
+			source:    "position fen rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1 moves e2e4\n",
+			fenString: "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1",
+			isFen:     true,
+			moves:     []string{"e2e4"},
+		},
+		{
+			source:    "position fen 8/8/8/8/8/8/8/K1k5 b - - 3 40\n",
+			fenString: "8/8/8/8/8/8/8/K1k5 b - - 3 40",
+			isFen:     true,
+		},
+		{
+			source:    "position fen r3k2r/8/8/8/8/8/8/R3K2R w KQkq -\n",
+			fenString: "r3k2r/8/8/8/8/8/8/R3K2R w KQkq -",
+			isFen:     true,
+		},
+	}
+
+	for _, expectation := range expectations {
+		statements, err := Parse(expectation.source)
+		if err != nil {
+			t.Fatal("error parsing test source", err)
+		}
+		if len(statements) != 1 {
+			t.Fatalf("expected 1 statement, but got %d", len(statements))
+		}
+		stmnt := statements[0]
+		if stmnt.Kind != PositionStatementKind {
+			t.Fatal("expected position statement, but got", stmnt.Kind)
+		}
+
+		if stmnt.Position.IsFen != expectation.isFen || stmnt.Position.IsStartPos != expectation.isStartPos {
+			t.Errorf("%q: expected fen %v and startpos %v", expectation.source, expectation.isFen, expectation.isStartPos)
+		}
+		if stmnt.Position.FenString != expectation.fenString {
+			t.Errorf("expected fen string %q, but got %q", expectation.fenString, stmnt.Position.FenString)
+		}
+		if strings.Join(stmnt.Position.Moves, " ") != strings.Join(expectation.moves, " ") {
+			t.Errorf("expected moves %v, but got %v", expectation.moves, stmnt.Position.Moves)
+		}
+	}
+}
+
+func TestStatementPerLine(t *testing.T) {
+	statements, err := Parse("joho debug on\nfoo bar\n  isready  \nregister later\nregister name Stefan MK code 4359874324\ngo\nstop")
+	if err != nil {
+		t.Fatal("error parsing test source", err)
+	}
+
+	expectedKinds := []StatementKind{DebugStatementKind, IsReadyStatementKind, RegisterStatementKind, RegisterStatementKind, GoStatementKind, StopStatementKind}
+	if len(statements) != len(expectedKinds) {
+		t.Fatalf("expected %d statements, but got %d", len(expectedKinds), len(statements))
+	}
+	for i, stmnt := range statements {
+		if stmnt.Kind != expectedKinds[i] {
+			t.Errorf("expected statement %d to be %s, but got %s", i, expectedKinds[i], stmnt.Kind)
+		}
+	}
+
+	if !statements[0].Debug.On {
+		t.Error("expected debug on")
+	}
+	if !statements[2].Register.IsLater {
+		t.Error("expected register later")
+	}
+	if statements[3].Register.Name != "Stefan MK" || statements[3].Register.Code != "4359874324" {
+		t.Errorf("expected name and code, but got %+v", statements[3].Register)
+	}
+}
+
+func TestGoSkipsUnknownTokens(t *testing.T) {
+	statements, err := Parse("go wtime 100 joho btime 200 searchmoves e2e4 d2d4 infinite\n")
+	if err != nil {
+		t.Fatal("error parsing test source", err)
+	}
+
+	stmnt := statements[0].Go
+	if stmnt.Wtime != 100 || stmnt.Btime != 200 {
+		t.Errorf("expected the clocks, but got %+v", stmnt)
+	}
+	if strings.Join(stmnt.SearchMoves, " ") != "e2e4 d2d4" {
+		t.Error("expected two search moves, but got", stmnt.SearchMoves)
+	}
+	if !inKinds(Go_inifiniteKind, stmnt.Kinds) {
+		t.Error("expected infinite")
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	expectations := []struct {
+		source  string
+		line    int
+		column  int
+		command string
+	}{
+		{"setoption", 1, 10, "setoption"},
+		{"setoption value 1\n", 1, 11, "setoption"},
+		{"go depth\n", 1, 9, "go"},
+		{"go depth deep\n", 1, 10, "go"},
+		{"debug joho on\n", 1, 7, "debug"},
+		{"isready\nposition startpos moves e2e4 e7e5 e9\n", 2, 35, "position"},
+		{"position\n", 1, 9, "position"},
+		{"register\n", 1, 9, "register"},
+	}
+
+	for _, expectation := range expectations {
+		_, err := Parse(expectation.source)
+		var parseErr *Error
+		if !errors.As(err, &parseErr) {
+			t.Errorf("%q: expected a parse error, but got %v", expectation.source, err)
+			continue
+		}
+		if parseErr.Line != expectation.line || parseErr.Column != expectation.column || parseErr.Command != expectation.command {
+			t.Errorf("%q: expected an error in %s at %d:%d, but got %s", expectation.source, expectation.command, expectation.line, expectation.column, parseErr)
+		}
+	}
+}
+
+func TestParseRecoversAfterError(t *testing.T) {
+	statements, err := Parse("go depth deep\nisready\n")
+	if err == nil {
+		t.Error("expected an error for the first line")
+	}
+	if len(statements) != 1 || statements[0].Kind != IsReadyStatementKind {
+		t.Errorf("expected the second line to be parsed, but got %v", statements)
+	}
+}